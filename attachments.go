@@ -0,0 +1,70 @@
+package spireclient
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "mime"
+    "mime/multipart"
+    "net/textproto"
+    "path/filepath"
+    "strings"
+)
+
+// Request body sent as is rather than encoded as JSON
+type rawBody struct {
+    contentType string
+    data []byte
+}
+
+// UploadAttachment attaches the document read from r, such as a PDF or an image, to the record
+// endpoint/{id}, sending it to endpoint/{id}/attachments as multipart/form-data under filename
+// The document is read into memory first so that retries can resend it
+// It returns the attachment's id from the Location header, or "" when Spire does not send one
+func (c *SpireClient) UploadAttachment(ctx context.Context, endpoint, id string, agent SpireAgent, filename string, r io.Reader) (string, error) {
+    body, err := multipartFile(filename, r)
+    if err != nil {
+        return "", err
+    }
+
+    raw, err := c.SpireRequestRaw(ctx, recordPath(endpoint, id)+"/attachments", agent, "POST", body)
+    if err != nil {
+        return "", err
+    }
+    if raw.Header.Get("Location") == "" {
+        return "", nil
+    }
+    return locationID(raw.Header.Get("Location"))
+}
+
+// UploadSalesOrderAttachment attaches a document to the sales order with the given id, see UploadAttachment
+func (c *SpireClient) UploadSalesOrderAttachment(ctx context.Context, id string, agent SpireAgent, filename string, r io.Reader) (string, error) {
+    return c.UploadAttachment(ctx, "/sales/orders", id, agent, filename, r)
+}
+
+// Encodes the contents of r as the "file" field of a multipart form, typed by the filename's extension
+func multipartFile(filename string, r io.Reader) (rawBody, error) {
+    var buf bytes.Buffer
+    form := multipart.NewWriter(&buf)
+
+    contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(filename)))
+    if contentType == "" {
+        contentType = "application/octet-stream"
+    }
+    header := make(textproto.MIMEHeader)
+    header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": filepath.Base(filename)}))
+    header.Set("Content-Type", contentType)
+
+    part, err := form.CreatePart(header)
+    if err != nil {
+        return rawBody{}, fmt.Errorf("error creating attachment part: %w", err)
+    }
+    if _, err := io.Copy(part, r); err != nil {
+        return rawBody{}, fmt.Errorf("error reading attachment %s: %w", filename, err)
+    }
+    if err := form.Close(); err != nil {
+        return rawBody{}, fmt.Errorf("error encoding attachment %s: %w", filename, err)
+    }
+    return rawBody{contentType: form.FormDataContentType(), data: buf.Bytes()}, nil
+}
@@ -0,0 +1,138 @@
+package spireclient
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
+)
+
+// DeleteResult reports the outcome of every delete in a batch
+type DeleteResult struct {
+    // Outcome per id, nil for a successful delete
+    Errors map[string]error
+}
+
+// Succeeded lists the ids that were deleted, sorted
+func (r DeleteResult) Succeeded() []string {
+    return r.ids(func(err error) bool { return err == nil })
+}
+
+// Failed lists the ids whose delete failed, sorted, e.g. to retry just those
+func (r DeleteResult) Failed() []string {
+    return r.ids(func(err error) bool { return err != nil })
+}
+
+// Err joins the errors of every failed delete, or returns nil when all succeeded
+func (r DeleteResult) Err() error {
+    var errs []error
+    for _, id := range r.Failed() {
+        errs = append(errs, fmt.Errorf("delete %s: %w", id, r.Errors[id]))
+    }
+    return errors.Join(errs...)
+}
+
+func (r DeleteResult) ids(match func(error) bool) []string {
+    var ids []string
+    for id, err := range r.Errors {
+        if match(err) {
+            ids = append(ids, id)
+        }
+    }
+    sort.Strings(ids)
+    return ids
+}
+
+// DeleteOptions customises a batch delete
+// The zero value deletes one record at a time
+type DeleteOptions struct {
+    // Deletes in flight at once, zero or one deletes sequentially
+    Concurrency int
+    // Counts a 404 as already deleted rather than a failure, making batch deletes safely re-runnable
+    IgnoreMissing bool
+}
+
+// Deletes endpoint/{id} for every id, carrying on past failures
+func (c *SpireClient) deleteBatch(ctx context.Context, endpoint string, ids []string, agent SpireAgent, opts DeleteOptions) (DeleteResult, error) {
+    result := DeleteResult{Errors: make(map[string]error, len(ids))}
+    var mu sync.Mutex
+
+    forEachConcurrently(len(ids), opts.Concurrency, func(i int) {
+        id := ids[i]
+        err := ctx.Err()
+        if err == nil {
+            err = c.DeleteRecord(ctx, endpoint, id, agent)
+            if opts.IgnoreMissing && errors.Is(err, ErrNotFound) {
+                err = nil
+            }
+        }
+        mu.Lock()
+        result.Errors[id] = err
+        mu.Unlock()
+    })
+    return result, result.Err()
+}
+
+// CreateResult is the outcome of one create in a batch, at the index of its payload
+type CreateResult struct {
+    // Id of the created record, taken from the Location header
+    ID string
+    // Nil when the record was created
+    Err error
+}
+
+// CreateOptions customises a batch create
+// The zero value creates one record at a time
+type CreateOptions struct {
+    // Creates in flight at once, zero or one creates sequentially
+    Concurrency int
+}
+
+// Creates a record from every payload with create, carrying on past failures
+// The error joins the failures, each labelled with the index of its payload
+func (c *SpireClient) createBatch(ctx context.Context, create func(context.Context, SpireAgent, interface{}) (string, error), payloads []interface{}, agent SpireAgent, opts CreateOptions) ([]CreateResult, error) {
+    results := make([]CreateResult, len(payloads))
+    forEachConcurrently(len(payloads), opts.Concurrency, func(i int) {
+        err := ctx.Err()
+        if err == nil {
+            results[i].ID, err = create(ctx, agent, payloads[i])
+        }
+        results[i].Err = err
+    })
+
+    var errs []error
+    for i, result := range results {
+        if result.Err != nil {
+            errs = append(errs, fmt.Errorf("create %d: %w", i, result.Err))
+        }
+    }
+    return results, errors.Join(errs...)
+}
+
+// Calls fn for every index below n with at most limit calls running at once
+func forEachConcurrently(n, limit int, fn func(i int)) {
+    if limit < 1 {
+        limit = 1
+    }
+    if limit > n {
+        limit = n
+    }
+
+    jobs := make(chan int)
+    var wg sync.WaitGroup
+    for w := 0; w < limit; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range jobs {
+                fn(i)
+            }
+        }()
+    }
+    for i := 0; i < n; i++ {
+        jobs <- i
+    }
+    close(jobs)
+    wg.Wait()
+}
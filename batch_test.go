@@ -0,0 +1,83 @@
+package spireclient
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "path"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestDeleteSalesOrdersAttemptsEveryID(t *testing.T) {
+    var mu sync.Mutex
+    attempted := map[string]int{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := path.Base(r.URL.Path)
+        mu.Lock()
+        attempted[id]++
+        mu.Unlock()
+        if r.Method != http.MethodDelete {
+            t.Errorf("got %s, want DELETE", r.Method)
+        }
+        if id == "2" || id == "4" {
+            http.Error(w, `{"message":"order is invoiced"}`, http.StatusInternalServerError)
+            return
+        }
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    defer server.Close()
+
+    ids := []string{"1", "2", "3", "4", "5"}
+    result, err := NewSpireClient(server.URL).DeleteSalesOrders(context.Background(), SpireAgent{}, ids)
+    if err == nil {
+        t.Error("got no error, want the failed deletes reported")
+    }
+    for _, id := range ids {
+        if attempted[id] != 1 {
+            t.Errorf("id %s deleted %d times, want 1", id, attempted[id])
+        }
+    }
+    if got := fmt.Sprint(result.Failed()); got != "[2 4]" {
+        t.Errorf("Failed() = %s, want [2 4]", got)
+    }
+    if got := fmt.Sprint(result.Succeeded()); got != "[1 3 5]" {
+        t.Errorf("Succeeded() = %s, want [1 3 5]", got)
+    }
+}
+
+func TestDeleteSalesOrdersConcurrencyBound(t *testing.T) {
+    var inFlight, peak, total atomic.Int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        n := inFlight.Add(1)
+        defer inFlight.Add(-1)
+        for {
+            p := peak.Load()
+            if n <= p || peak.CompareAndSwap(p, n) {
+                break
+            }
+        }
+        total.Add(1)
+        time.Sleep(20 * time.Millisecond)
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    defer server.Close()
+
+    ids := make([]string, 12)
+    for i := range ids {
+        ids[i] = fmt.Sprint(i + 1)
+    }
+    _, err := NewSpireClient(server.URL).DeleteSalesOrdersWithOptions(context.Background(), SpireAgent{}, ids, DeleteOptions{Concurrency: 3})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if total.Load() != 12 {
+        t.Errorf("sent %d deletes, want 12", total.Load())
+    }
+    if p := peak.Load(); p > 3 || p < 2 {
+        t.Errorf("peak of %d deletes in flight, want at most 3 and more than 1", p)
+    }
+}
@@ -0,0 +1,86 @@
+package spireclient
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// Time an open circuit rejects requests when SpireClient.BreakerCooldown is zero
+const defaultBreakerCooldown = 30 * time.Second
+
+// ErrCircuitOpen is returned without contacting Spire while the circuit breaker is open
+var ErrCircuitOpen = errors.New("spire circuit breaker open")
+
+// Counts consecutive failures and opens once SpireClient.BreakerThreshold is reached
+// After the cooldown a single probe request is let through: success closes the circuit, failure reopens it
+type circuitBreaker struct {
+    mu sync.Mutex
+    failures int
+    firstFailure time.Time
+    openUntil time.Time
+    probing bool
+}
+
+// Returns ErrCircuitOpen while the circuit is open or another request is probing it
+func (c *SpireClient) allowRequest() error {
+    if c.BreakerThreshold <= 0 {
+        return nil
+    }
+    b := &c.breaker
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if b.openUntil.IsZero() {
+        return nil
+    }
+    if time.Now().Before(b.openUntil) || b.probing {
+        return ErrCircuitOpen
+    }
+    b.probing = true
+    return nil
+}
+
+// Records the outcome of an attempt; connection errors and 5xx responses count as failures, while
+// an attempt abandoned because ctx ended counts as neither
+func (c *SpireClient) recordOutcome(ctx context.Context, resp *http.Response, err error) {
+    if c.BreakerThreshold <= 0 {
+        return
+    }
+    b := &c.breaker
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if err != nil && ctx.Err() != nil {
+        b.probing = false
+        return
+    }
+    if err == nil && resp.StatusCode < http.StatusInternalServerError {
+        b.failures = 0
+        b.openUntil = time.Time{}
+        b.probing = false
+        return
+    }
+
+    now := time.Now()
+    cooldown := c.BreakerCooldown
+    if cooldown <= 0 {
+        cooldown = defaultBreakerCooldown
+    }
+    if b.probing {
+        b.probing = false
+        b.openUntil = now.Add(cooldown)
+        return
+    }
+    if b.failures == 0 || (c.BreakerWindow > 0 && now.Sub(b.firstFailure) > c.BreakerWindow) {
+        b.failures = 0
+        b.firstFailure = now
+    }
+    b.failures++
+    if b.failures >= c.BreakerThreshold {
+        b.failures = 0
+        b.openUntil = now.Add(cooldown)
+    }
+}
@@ -0,0 +1,69 @@
+package spireclient
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestBreakerProbeSurvivesCancelledRateLimitWait(t *testing.T) {
+    var healthy atomic.Bool
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !healthy.Load() {
+            w.WriteHeader(http.StatusInternalServerError)
+            return
+        }
+        w.Write([]byte(`{"records":[],"count":0}`))
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL, WithCircuitBreaker(1, 0, 50*time.Millisecond), WithRateLimit(5, 1))
+    if _, err := client.FetchSpireData("/sales/orders", nil, SpireAgent{}); err == nil {
+        t.Fatal("expected the failing request to return an error")
+    }
+    healthy.Store(true)
+    time.Sleep(60 * time.Millisecond)
+
+    // Cancelled while waiting for a token, after the cooldown has ended
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    if _, err := client.FetchSpireDataContext(ctx, "/sales/orders", nil, SpireAgent{}); !errors.Is(err, context.DeadlineExceeded) {
+        t.Fatalf("got %v, want a deadline error", err)
+    }
+
+    if _, err := client.FetchSpireData("/sales/orders", nil, SpireAgent{}); err != nil {
+        t.Fatalf("request after recovery failed: %v", err)
+    }
+}
+
+func TestBreakerProbeSurvivesInvalidRequest(t *testing.T) {
+    var healthy atomic.Bool
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !healthy.Load() {
+            w.WriteHeader(http.StatusInternalServerError)
+            return
+        }
+        w.Write([]byte(`{"records":[],"count":0}`))
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL, WithCircuitBreaker(1, 0, 50*time.Millisecond))
+    if _, err := client.FetchSpireData("/sales/orders", nil, SpireAgent{}); err == nil {
+        t.Fatal("expected the failing request to return an error")
+    }
+    healthy.Store(true)
+    time.Sleep(60 * time.Millisecond)
+
+    // A request that can't be built never reaches the server, so it must not take the probe
+    if _, err := client.SpireRequestContext(context.Background(), "/sales/orders", SpireAgent{}, "BAD METHOD", nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+        t.Fatalf("got %v, want the invalid method reported", err)
+    }
+
+    if _, err := client.FetchSpireData("/sales/orders", nil, SpireAgent{}); err != nil {
+        t.Fatalf("request after recovery failed: %v", err)
+    }
+}
@@ -0,0 +1,107 @@
+package spireclient
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Entries kept by the response cache when SpireClient.CacheSize is zero
+const defaultCacheSize = 256
+
+type responseCache struct {
+    mu sync.Mutex
+    entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+    records []map[string]interface{}
+    expires time.Time
+}
+
+func (r *responseCache) get(key string) ([]map[string]interface{}, bool) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    entry, ok := r.entries[key]
+    if !ok {
+        return nil, false
+    }
+    if !time.Now().Before(entry.expires) {
+        delete(r.entries, key)
+        return nil, false
+    }
+    return append([]map[string]interface{}(nil), entry.records...), true
+}
+
+// Stores records under key, evicting expired entries and then the entry closest to expiry when full
+func (r *responseCache) put(key string, records []map[string]interface{}, ttl time.Duration, size int) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if r.entries == nil {
+        r.entries = make(map[string]cacheEntry)
+    }
+
+    now := time.Now()
+    if _, exists := r.entries[key]; !exists && len(r.entries) >= size {
+        for k, entry := range r.entries {
+            if !now.Before(entry.expires) {
+                delete(r.entries, k)
+            }
+        }
+        for len(r.entries) >= size {
+            var oldest string
+            var oldestExpiry time.Time
+            for k, entry := range r.entries {
+                if oldest == "" || entry.expires.Before(oldestExpiry) {
+                    oldest, oldestExpiry = k, entry.expires
+                }
+            }
+            delete(r.entries, oldest)
+        }
+    }
+    r.entries[key] = cacheEntry{
+        records: append([]map[string]interface{}(nil), records...),
+        expires: now.Add(ttl),
+    }
+}
+
+func (r *responseCache) clear() {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.entries = nil
+}
+
+// ClearCache drops every response cached by FetchSpireData, see SpireClient.CacheTTL
+func (c *SpireClient) ClearCache() {
+    c.cache.clear()
+}
+
+// Cache key covering everything that shapes the result: who asked, where, which filter and which options
+// Credentials are hashed so that an agent with a wrong password never reads another's cached records
+func cacheKey(endpoint, filter string, agent SpireAgent, opts FetchOptions) string {
+    hash := sha256.Sum256([]byte(agent.Username + ":" + agent.Password + ":" + agent.Token))
+    var b strings.Builder
+    b.WriteString(hex.EncodeToString(hash[:]))
+    b.WriteString("\n")
+    b.WriteString(endpoint)
+    b.WriteString("\n")
+    b.WriteString(filter)
+    b.WriteString("\n")
+    for _, s := range opts.Sort {
+        if s.Descending {
+            b.WriteString("-")
+        }
+        b.WriteString(s.Field)
+        b.WriteString(",")
+    }
+    b.WriteString("\n")
+    b.WriteString(strings.Join(opts.Fields, ","))
+    b.WriteString("\n")
+    b.WriteString(strconv.Itoa(opts.Start))
+    b.WriteString("\n")
+    b.WriteString(strconv.FormatBool(opts.IncludeInactive))
+    return b.String()
+}
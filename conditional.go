@@ -0,0 +1,89 @@
+package spireclient
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+)
+
+// Conditional carries the validators of a previous fetch, as returned in ConditionalResult
+// Zero fields are not sent
+type Conditional struct {
+    // Sent as If-None-Match
+    ETag string
+    // Sent as If-Modified-Since
+    LastModified time.Time
+}
+
+// ConditionalResult is the outcome of FetchSpireDataIfChanged
+type ConditionalResult struct {
+    // Every record, nil when NotModified
+    Records []map[string]interface{}
+    // The server answered 304: nothing changed since the validators were issued
+    NotModified bool
+    // Validators of this response to persist and pass to the next call, empty when the server sends none
+    // When NotModified they are the ones passed in
+    ETag string
+    LastModified time.Time
+}
+
+// FetchSpireDataIfChanged is FetchSpireDataWithOptions sending If-None-Match and If-Modified-Since with
+// the first page, for polling syncs that want to skip unchanged data
+// A 304 from the server returns a result with NotModified set instead of any records; otherwise every
+// page is fetched as usual and the first page's ETag and Last-Modified headers are returned
+func (c *SpireClient) FetchSpireDataIfChanged(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions, cond Conditional) (ConditionalResult, error) {
+    limit, err := c.pageLimit(opts)
+    if err != nil {
+        return ConditionalResult{}, err
+    }
+    firstURL, _, err := c.listURL(endpoint, filters, limit, opts)
+    if err != nil {
+        return ConditionalResult{}, err
+    }
+
+    firstCtx := ctx
+    if cond.ETag != "" {
+        firstCtx = withRequestHeader(firstCtx, "If-None-Match", cond.ETag)
+    }
+    if !cond.LastModified.IsZero() {
+        firstCtx = withRequestHeader(firstCtx, "If-Modified-Since", cond.LastModified.UTC().Format(http.TimeFormat))
+    }
+
+    first, err := SpireRequestGenericContext[map[string]interface{}](firstCtx, c, firstURL.String(), agent, "GET", nil)
+    var spireErr *SpireError
+    if errors.As(err, &spireErr) && spireErr.StatusCode == http.StatusNotModified {
+        return ConditionalResult{NotModified: true, ETag: cond.ETag, LastModified: cond.LastModified}, nil
+    }
+    if err != nil {
+        return ConditionalResult{}, fmt.Errorf("error making initial Spire request: %w", err)
+    }
+
+    result := ConditionalResult{Records: first.Records, ETag: first.Header.Get("ETag")}
+    if lastModified, err := http.ParseTime(first.Header.Get("Last-Modified")); err == nil {
+        result.LastModified = lastModified
+    }
+
+    received := opts.Start + len(first.Records)
+    if len(first.Records) == 0 || received >= first.Count {
+        return result, nil
+    }
+    rest := opts
+    rest.Start = received
+    if rest.Limit == 0 {
+        rest.Limit = len(first.Records)
+    }
+    more, err := FetchSpireDataTypedWithOptions[map[string]interface{}](ctx, c, endpoint, filters, agent, rest)
+    result.Records = append(result.Records, more...)
+    if err != nil {
+        var partial *PartialResultError
+        if !errors.As(err, &partial) {
+            return result, &PartialResultError{Received: len(result.Records), Total: first.Count, Next: received, Err: err}
+        }
+        partial.Received = len(result.Records)
+        partial.Total = first.Count
+        return result, partial
+    }
+    return result, nil
+}
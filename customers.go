@@ -0,0 +1,28 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const customersEndpoint = "/customers"
+
+// GetCustomer fetches a single customer by its numeric id
+// A missing customer returns an error matching ErrNotFound
+func (c *SpireClient) GetCustomer(ctx context.Context, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.GetRecord(ctx, customersEndpoint, id, agent)
+}
+
+// ListCustomers gets ALL customers matching filters, paginating like FetchSpireData
+func (c *SpireClient) ListCustomers(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, customersEndpoint, filters, agent)
+}
+
+// CreateCustomer creates a customer and returns its id, taken from the Location header
+func (c *SpireClient) CreateCustomer(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    return c.CreateRecordWithID(ctx, customersEndpoint, agent, payload)
+}
+
+// UpdateCustomer replaces the customer with the given id using a PUT of the full customer
+func (c *SpireClient) UpdateCustomer(ctx context.Context, id string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.UpdateRecord(ctx, customersEndpoint, id, agent, payload)
+}
@@ -0,0 +1,70 @@
+package spireclient
+
+import (
+    "context"
+    "net/http"
+)
+
+// Endpoints probed by Discover, one for each resource this package wraps
+var discoverEndpoints = []string{
+    "/customers",
+    "/vendors",
+    "/sales/orders",
+    "/sales/items",
+    "/sales/invoices",
+    "/sales/invoice_items",
+    "/sales/shipments",
+    "/inventory/items",
+    "/inventory/adjustments",
+    "/inventory/price_matrix",
+    "/purchasing/orders",
+    "/ar/payments",
+    "/gl/accounts",
+    "/gl/transactions",
+}
+
+// EndpointStatus reports whether a single endpoint is available on the server
+type EndpointStatus struct {
+    Endpoint string
+    // The server answered the probe with 200
+    Available bool
+    // Zero when the server was unreachable, 404 typically means the module is not enabled
+    StatusCode int
+    // Why the endpoint is not Available, nil when it is
+    Err error
+}
+
+// Discover probes every resource this package wraps, such as /sales/orders or /gl/accounts, with a
+// single-record GET and reports which the server makes available, e.g. to skip disabled modules
+// A 401 or 403 on every endpoint points at the credentials rather than the modules
+func (c *SpireClient) Discover(ctx context.Context, agent SpireAgent) []EndpointStatus {
+    return c.DiscoverEndpoints(ctx, agent, discoverEndpoints)
+}
+
+// DiscoverEndpoints is Discover probing the given endpoints, returning their statuses in the same order
+// Up to SpireClient.Concurrency probes run at once
+func (c *SpireClient) DiscoverEndpoints(ctx context.Context, agent SpireAgent, endpoints []string) []EndpointStatus {
+    statuses := make([]EndpointStatus, len(endpoints))
+    forEachConcurrently(len(endpoints), c.Concurrency, func(i int) {
+        statuses[i] = c.probe(ctx, agent, endpoints[i])
+    })
+    return statuses
+}
+
+// Requests a single record of endpoint
+func (c *SpireClient) probe(ctx context.Context, agent SpireAgent, endpoint string) EndpointStatus {
+    status := EndpointStatus{Endpoint: endpoint}
+    probeURL, _, err := c.listURL(endpoint, nil, 1, FetchOptions{})
+    if err != nil {
+        status.Err = err
+        return status
+    }
+
+    resp, _, err := c.doBody(ctx, probeURL.String(), agent, "GET", nil)
+    if resp != nil {
+        status.StatusCode = resp.StatusCode
+    }
+    status.Err = err
+    status.Available = err == nil && status.StatusCode == http.StatusOK
+    return status
+}
@@ -0,0 +1,41 @@
+package spireclient
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "path"
+)
+
+// Id reported in the Location header of a dry-run create
+const DryRunID = "dry-run"
+
+// Logs a mutating request that a dry run skips and answers it as Spire would on success:
+// 201 with a Location ending in DryRunID for a POST, 204 for anything else
+func dryRunResponse(ctx context.Context, method, target string, payload []byte) (*http.Response, error) {
+    req, err := http.NewRequestWithContext(ctx, method, target, nil)
+    if err != nil {
+        return nil, fmt.Errorf("error creating request: %w", err)
+    }
+    log.Printf("Dry run: skipping %s %s with a %d byte payload", method, req.URL.Redacted(), len(payload))
+
+    resp := &http.Response{
+        Status: "204 No Content",
+        StatusCode: http.StatusNoContent,
+        Proto: "HTTP/1.1",
+        ProtoMajor: 1,
+        ProtoMinor: 1,
+        Header: make(http.Header),
+        Body: http.NoBody,
+        Request: req,
+    }
+    if method == http.MethodPost {
+        resp.Status = "201 Created"
+        resp.StatusCode = http.StatusCreated
+        location := url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: path.Join(req.URL.Path, DryRunID)}
+        resp.Header.Set("Location", location.String())
+    }
+    return resp, nil
+}
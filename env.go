@@ -0,0 +1,54 @@
+package spireclient
+
+import (
+    "fmt"
+    "os"
+)
+
+// Environment variables read by AgentFromEnv and ClientFromEnv
+const (
+    EnvURL = "SPIRE_URL"
+    EnvUsername = "SPIRE_USERNAME"
+    EnvPassword = "SPIRE_PASSWORD"
+    // Optional, applied with WithCompany when set
+    EnvCompany = "SPIRE_COMPANY"
+)
+
+// AgentFromEnv builds a SpireAgent from SPIRE_USERNAME and SPIRE_PASSWORD, failing if either is unset or empty
+func AgentFromEnv() (SpireAgent, error) {
+    username, err := requireEnv(EnvUsername)
+    if err != nil {
+        return SpireAgent{}, err
+    }
+    password, err := requireEnv(EnvPassword)
+    if err != nil {
+        return SpireAgent{}, err
+    }
+    return SpireAgent{Username: username, Password: password}, nil
+}
+
+// ClientFromEnv builds a client for SPIRE_URL along with the agent from AgentFromEnv
+// SPIRE_COMPANY, when set, selects the company as WithCompany does; opts are applied after it
+func ClientFromEnv(opts ...Option) (*SpireClient, SpireAgent, error) {
+    rootURL, err := requireEnv(EnvURL)
+    if err != nil {
+        return nil, SpireAgent{}, err
+    }
+    agent, err := AgentFromEnv()
+    if err != nil {
+        return nil, SpireAgent{}, err
+    }
+    if company := os.Getenv(EnvCompany); company != "" {
+        opts = append([]Option{WithCompany(company)}, opts...)
+    }
+    return NewSpireClient(rootURL, opts...), agent, nil
+}
+
+// Value of the environment variable name, or an error naming it when it is unset or empty
+func requireEnv(name string) (string, error) {
+    value := os.Getenv(name)
+    if value == "" {
+        return "", fmt.Errorf("environment variable %s is not set", name)
+    }
+    return value, nil
+}
@@ -0,0 +1,92 @@
+package spireclient
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "sync"
+)
+
+// Fetches the pages after the first with at most c.Concurrency requests in flight, returning
+// their records in offset order
+// Offsets start at first and step by the size of the first page, which is the page size the server
+// actually honours
+// The first failing page cancels the others and its error is returned, together with the records of the
+// pages before it that completed
+func fetchPagesConcurrently[T any](ctx context.Context, c *SpireClient, baseURL url.URL, q url.Values, agent SpireAgent, first, pageSize, count int) ([]T, error) {
+    var starts []int
+    for start := first; start < count; start += pageSize {
+        starts = append(starts, start)
+    }
+
+    fetchCtx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    pages := make([][]T, len(starts))
+    done := make([]bool, len(starts))
+    jobs := make(chan int)
+    var (
+        wg sync.WaitGroup
+        errOnce sync.Once
+        firstErr error
+    )
+
+    workers := c.Concurrency
+    if workers > len(starts) {
+        workers = len(starts)
+    }
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := range jobs {
+                pageQuery := url.Values{}
+                for k, v := range q {
+                    pageQuery[k] = append([]string(nil), v...)
+                }
+                pageQuery.Set("start", fmt.Sprintf("%d", starts[i]))
+                pageURL := baseURL
+                pageURL.RawQuery = pageQuery.Encode()
+
+                page, err := SpireRequestGenericContext[T](fetchCtx, c, pageURL.String(), agent, "GET", nil)
+                if err != nil {
+                    errOnce.Do(func() {
+                        firstErr = fmt.Errorf("error making Spire request starting at %d: %w", starts[i], err)
+                        cancel()
+                    })
+                    continue
+                }
+                pages[i] = page.Records
+                done[i] = true
+            }
+        }()
+    }
+
+    for i := range starts {
+        select {
+        case jobs <- i:
+        case <-fetchCtx.Done():
+        }
+        if fetchCtx.Err() != nil {
+            break
+        }
+    }
+    close(jobs)
+    wg.Wait()
+
+    var records []T
+    for i, page := range pages {
+        if !done[i] {
+            break
+        }
+        records = append(records, page...)
+    }
+
+    if firstErr != nil {
+        return records, firstErr
+    }
+    if err := ctx.Err(); err != nil {
+        return records, fmt.Errorf("fetch cancelled: %w", err)
+    }
+    return records, nil
+}
@@ -0,0 +1,88 @@
+package spireclient
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestFetchPagesConcurrentlyKeepsOffsetOrder(t *testing.T) {
+    var inFlight, peak atomic.Int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        n := inFlight.Add(1)
+        defer inFlight.Add(-1)
+        for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+        }
+
+        start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+        // Later pages answer first, so arrival order is the reverse of offset order
+        time.Sleep(time.Duration(10-start) * 5 * time.Millisecond)
+        fmt.Fprintf(w, `{"records":[{"id":%d},{"id":%d}],"count":10}`, start, start+1)
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL, WithPageLimit(2))
+    client.Concurrency = 4
+    records, err := client.FetchSpireData("/inventory/items", nil, SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(records) != 10 {
+        t.Fatalf("got %d records, want 10", len(records))
+    }
+    for i, record := range records {
+        if id := record["id"]; id != float64(i) {
+            t.Fatalf("record %d has id %v, want records in offset order", i, id)
+        }
+    }
+    if p := peak.Load(); p < 2 || p > 4 {
+        t.Errorf("peak of %d page requests in flight, want more than 1 and at most Concurrency 4", p)
+    }
+}
+
+func TestFetchPagesConcurrentlyCancelsOthersOnError(t *testing.T) {
+    var cancelled atomic.Int32
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+        switch start {
+        case 0:
+            fmt.Fprint(w, `{"records":[{"id":0},{"id":1}],"count":10}`)
+        case 2:
+            http.Error(w, `{"message":"bad page"}`, http.StatusBadRequest)
+        default:
+            select {
+            case <-r.Context().Done():
+                cancelled.Add(1)
+            case <-time.After(5 * time.Second):
+                fmt.Fprintf(w, `{"records":[{"id":%d},{"id":%d}],"count":10}`, start, start+1)
+            }
+        }
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL, WithPageLimit(2))
+    client.Concurrency = 4
+    started := time.Now()
+    records, err := client.FetchSpireData("/inventory/items", nil, SpireAgent{})
+    if elapsed := time.Since(started); elapsed > 2*time.Second {
+        t.Errorf("returned after %v, want the failed page to cancel the others", elapsed)
+    }
+
+    var partial *PartialResultError
+    if !errors.As(err, &partial) {
+        t.Fatalf("got %v, want a *PartialResultError", err)
+    }
+    if len(records) != 2 || partial.Next != 2 {
+        t.Errorf("got %d records resuming at %d, want the 2 before the failed page", len(records), partial.Next)
+    }
+
+    server.Close()
+    if cancelled.Load() == 0 {
+        t.Error("no in-flight page saw its request cancelled")
+    }
+}
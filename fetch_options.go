@@ -0,0 +1,91 @@
+package spireclient
+
+import (
+    "fmt"
+    "net/url"
+    "strings"
+)
+
+// FetchOptions customises a FetchSpireDataWithOptions call
+// The zero value fetches exactly like FetchSpireData
+type FetchOptions struct {
+    // Records requested per page, overriding SpireClient.PageLimit when non-zero
+    Limit int
+    // Sort order, most significant field first
+    Sort []SortField
+    // Restricts each record to these fields, all fields are returned when empty
+    Fields []string
+    // Offset of the first record to fetch, e.g. PartialResultError.Next to resume a pull that failed partway
+    Start int
+    // Asks for inactive and archived records too, sent as includeInactive=true
+    // Servers that don't hide inactive records ignore it, so filter on status to exclude them there
+    IncludeInactive bool
+
+    // Set by lookups that must see the server's current records, such as the existence check of
+    // CreateSalesOrderIdempotent, so that they never read from the response cache nor have
+    // SpireClient.DefaultFilters narrow what they find
+    lookup bool
+}
+
+// SortField orders records by a single field
+type SortField struct {
+    Field string
+    Descending bool
+}
+
+// Ascending sorts by field from lowest to highest
+func Ascending(field string) SortField {
+    return SortField{Field: field}
+}
+
+// Descending sorts by field from highest to lowest, e.g. newest first for a date
+func Descending(field string) SortField {
+    return SortField{Field: field, Descending: true}
+}
+
+// Spire takes one sort parameter per field, prefixed with "-" for descending order
+func (s SortField) param() string {
+    if s.Descending {
+        return "-" + s.Field
+    }
+    return s.Field
+}
+
+// Adds the query parameters for the options other than the limit
+func (o FetchOptions) apply(q url.Values) {
+    for _, sort := range o.Sort {
+        q.Add("sort", sort.param())
+    }
+    if len(o.Fields) > 0 {
+        q.Set("fields", strings.Join(o.Fields, ","))
+    }
+    if o.IncludeInactive {
+        q.Set("includeInactive", "true")
+    }
+}
+
+// Resolves the page size from the options, then the client, then the default
+func (c *SpireClient) pageLimit(opts FetchOptions) (int, error) {
+    limit := opts.Limit
+    if limit == 0 {
+        limit = c.PageLimit
+    }
+    if limit == 0 {
+        limit = defaultPageLimit
+    }
+    if limit < 0 {
+        return 0, fmt.Errorf("invalid page limit %d: must be greater than 0", limit)
+    }
+    return limit, nil
+}
+
+// Pages a single fetch may request, zero when uncapped
+func (c *SpireClient) maxPages() int {
+    switch {
+    case c.MaxPages == 0:
+        return defaultMaxPages
+    case c.MaxPages < 0:
+        return 0
+    }
+    return c.MaxPages
+}
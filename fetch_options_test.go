@@ -0,0 +1,36 @@
+package spireclient
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestSortFieldsEncodeInOrder(t *testing.T) {
+    opts := FetchOptions{Sort: []SortField{{"orderDate", true}, {"orderNo", false}}}
+
+    u, _, err := NewSpireClient("http://spire.test").listURL("/sales/orders", nil, 10, opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(u.RawQuery, "sort=-orderDate&sort=orderNo") {
+        t.Errorf("listURL query = %s, want sort=-orderDate&sort=orderNo", u.RawQuery)
+    }
+
+    var rawQuery string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        rawQuery = r.URL.RawQuery
+        w.Write([]byte(`{"records":[],"count":0}`))
+    }))
+    defer server.Close()
+
+    opts.Sort = append(opts.Sort, Ascending("customer name"))
+    if _, err := NewSpireClient(server.URL).FetchPage(context.Background(), "/sales/orders", nil, SpireAgent{}, opts); err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(rawQuery, "sort=-orderDate&sort=orderNo&sort=customer+name") {
+        t.Errorf("FetchPage sent query %s, want sort=-orderDate&sort=orderNo&sort=customer+name", rawQuery)
+    }
+}
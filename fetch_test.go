@@ -0,0 +1,67 @@
+package spireclient
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "testing"
+)
+
+func TestFetchSpireDataCollectsEveryPage(t *testing.T) {
+    var starts []int
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+        starts = append(starts, start)
+        if limit := r.URL.Query().Get("limit"); limit != "2" {
+            t.Errorf("limit = %s, want 2", limit)
+        }
+        records := ""
+        for id := start; id < min(start+2, 5); id++ {
+            if records != "" {
+                records += ","
+            }
+            records += fmt.Sprintf(`{"id":%d}`, id)
+        }
+        fmt.Fprintf(w, `{"records":[%s],"count":5}`, records)
+    }))
+    defer server.Close()
+
+    records, err := NewSpireClient(server.URL, WithPageLimit(2)).FetchSpireData("/inventory/items", nil, SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(records) != 5 {
+        t.Fatalf("got %d records, want 5", len(records))
+    }
+    for i, record := range records {
+        if id := record["id"]; id != float64(i) {
+            t.Errorf("record %d has id %v, want %d", i, id, i)
+        }
+    }
+    if fmt.Sprint(starts) != "[0 2 4]" {
+        t.Errorf("pages requested from %v, want [0 2 4]", starts)
+    }
+}
+
+func TestFetchCorruptedCountStopsAtMaxPages(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+        fmt.Fprintf(w, `{"records":[{"id":%d},{"id":%d}],"count":9000000000000000000}`, start, start+1)
+    }))
+    defer server.Close()
+
+    for _, concurrency := range []int{0, 4} {
+        client := NewSpireClient(server.URL, WithMaxPages(3), WithPageLimit(2))
+        client.Concurrency = concurrency
+        records, err := client.FetchSpireData("/inventory/items", nil, SpireAgent{})
+        if !errors.Is(err, ErrMaxPagesExceeded) {
+            t.Fatalf("concurrency %d: got %v, want ErrMaxPagesExceeded", concurrency, err)
+        }
+        var partial *PartialResultError
+        if !errors.As(err, &partial) || len(records) != 6 {
+            t.Fatalf("concurrency %d: got %d records and %v, want 6 with a *PartialResultError", concurrency, len(records), err)
+        }
+    }
+}
@@ -0,0 +1,300 @@
+package spireclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "sort"
+)
+
+// Filter is a single Spire filter clause
+// It is a plain map, so it can be passed anywhere a filters map is accepted
+type Filter map[string]interface{}
+
+// Equals matches records whose field equals value
+func Equals(field string, value interface{}) Filter {
+    return Filter{field: value}
+}
+
+// In matches records whose field equals any of values
+func In(field string, values ...interface{}) Filter {
+    return Filter{field: map[string]interface{}{"$in": values}}
+}
+
+// GreaterThan matches records whose field is strictly greater than value
+func GreaterThan(field string, value interface{}) Filter {
+    return Filter{field: map[string]interface{}{"$gt": value}}
+}
+
+// LessThan matches records whose field is strictly less than value
+func LessThan(field string, value interface{}) Filter {
+    return Filter{field: map[string]interface{}{"$lt": value}}
+}
+
+// Between matches records whose field lies within low and high, inclusive
+func Between(field string, low, high interface{}) Filter {
+    return Filter{field: map[string]interface{}{"$gte": low, "$lte": high}}
+}
+
+// Contains matches records whose field contains text, ignoring case
+// text is matched literally: regular expression characters such as "(" or "." carry no special meaning
+func Contains(field, text string) Filter {
+    return Filter{field: map[string]interface{}{"$regex": "(?i)" + regexp.QuoteMeta(text)}}
+}
+
+// StartsWith matches records whose field starts with prefix, ignoring case
+// prefix is matched literally like the text of Contains
+func StartsWith(field, prefix string) Filter {
+    return Filter{field: map[string]interface{}{"$regex": "(?i)^" + regexp.QuoteMeta(prefix)}}
+}
+
+// And matches records satisfying every filter
+func And(filters ...Filter) Filter {
+    return Filter{"$and": filters}
+}
+
+// Or matches records satisfying at least one filter
+func Or(filters ...Filter) Filter {
+    return Filter{"$or": filters}
+}
+
+// FilterBuilder accumulates clauses that must all match
+//
+//  filter := spireclient.NewFilterBuilder().
+//      Equals("whse", "00").
+//      Between("onHand", 1, 100).
+//      Or(spireclient.Equals("status", 0), spireclient.Equals("status", 1)).
+//      Build()
+type FilterBuilder struct {
+    clauses []Filter
+}
+
+// FilterBuilder constructor
+func NewFilterBuilder() *FilterBuilder {
+    return &FilterBuilder{}
+}
+
+// Equals adds a clause matching records whose field equals value
+func (b *FilterBuilder) Equals(field string, value interface{}) *FilterBuilder {
+    return b.add(Equals(field, value))
+}
+
+// In adds a clause matching records whose field equals any of values
+func (b *FilterBuilder) In(field string, values ...interface{}) *FilterBuilder {
+    return b.add(In(field, values...))
+}
+
+// GreaterThan adds a clause matching records whose field is strictly greater than value
+func (b *FilterBuilder) GreaterThan(field string, value interface{}) *FilterBuilder {
+    return b.add(GreaterThan(field, value))
+}
+
+// LessThan adds a clause matching records whose field is strictly less than value
+func (b *FilterBuilder) LessThan(field string, value interface{}) *FilterBuilder {
+    return b.add(LessThan(field, value))
+}
+
+// Between adds a clause matching records whose field lies within low and high, inclusive
+func (b *FilterBuilder) Between(field string, low, high interface{}) *FilterBuilder {
+    return b.add(Between(field, low, high))
+}
+
+// Contains adds a clause matching records whose field contains text, ignoring case
+func (b *FilterBuilder) Contains(field, text string) *FilterBuilder {
+    return b.add(Contains(field, text))
+}
+
+// StartsWith adds a clause matching records whose field starts with prefix, ignoring case
+func (b *FilterBuilder) StartsWith(field, prefix string) *FilterBuilder {
+    return b.add(StartsWith(field, prefix))
+}
+
+// And adds a clause requiring every one of filters to match
+func (b *FilterBuilder) And(filters ...Filter) *FilterBuilder {
+    return b.add(And(filters...))
+}
+
+// Or adds a clause requiring at least one of filters to match
+func (b *FilterBuilder) Or(filters ...Filter) *FilterBuilder {
+    return b.add(Or(filters...))
+}
+
+func (b *FilterBuilder) add(f Filter) *FilterBuilder {
+    b.clauses = append(b.clauses, f)
+    return b
+}
+
+// Build combines the clauses into a single filter for FetchSpireData
+// Clauses on different fields, and operator clauses on the same field such as GreaterThan and LessThan,
+// are merged into one object; any other overlap falls back to an explicit $and
+func (b *FilterBuilder) Build() Filter {
+    if len(b.clauses) == 0 {
+        return nil
+    }
+
+    merged := Filter{}
+    for _, clause := range b.clauses {
+        for field, value := range clause {
+            existing, ok := merged[field]
+            if !ok {
+                merged[field] = value
+                continue
+            }
+            combined, ok := mergeOperators(existing, value)
+            if !ok {
+                return And(b.clauses...)
+            }
+            merged[field] = combined
+        }
+    }
+    return merged
+}
+
+// Merges two operator objects on the same field, failing if either is not an operator object or an operator repeats
+func mergeOperators(a, b interface{}) (map[string]interface{}, bool) {
+    aOps, ok := a.(map[string]interface{})
+    if !ok {
+        return nil, false
+    }
+    bOps, ok := b.(map[string]interface{})
+    if !ok {
+        return nil, false
+    }
+
+    combined := make(map[string]interface{}, len(aOps)+len(bOps))
+    for op, v := range aOps {
+        combined[op] = v
+    }
+    for op, v := range bOps {
+        if _, dup := combined[op]; dup {
+            return nil, false
+        }
+        combined[op] = v
+    }
+    return combined, true
+}
+
+// Filter sent for endpoint: the caller's filters merged over SpireClient.DefaultFilters by top-level key
+func (c *SpireClient) effectiveFilter(endpoint string, filters map[string]interface{}) map[string]interface{} {
+    defaults := c.DefaultFilters[endpoint]
+    if len(defaults) == 0 {
+        return filters
+    }
+    merged := make(map[string]interface{}, len(defaults)+len(filters))
+    for key, value := range defaults {
+        merged[key] = value
+    }
+    for key, value := range filters {
+        merged[key] = value
+    }
+    return merged
+}
+
+// Copy of a DefaultFilters map, sharing the filters themselves
+func copyDefaultFilters(defaults map[string]Filter) map[string]Filter {
+    if defaults == nil {
+        return nil
+    }
+    copied := make(map[string]Filter, len(defaults))
+    for endpoint, filter := range defaults {
+        copied[endpoint] = filter
+    }
+    return copied
+}
+
+// Operators ValidateFilter accepts, and whether each takes a list of filters or of values
+var filterOperators = map[string]operatorKind{
+    "$and": filterList,
+    "$or": filterList,
+    "$not": filterValue,
+    "$eq": scalarValue,
+    "$ne": scalarValue,
+    "$lt": scalarValue,
+    "$lte": scalarValue,
+    "$gt": scalarValue,
+    "$gte": scalarValue,
+    "$in": valueList,
+    "$nin": valueList,
+    "$regex": scalarValue,
+    "$like": scalarValue,
+}
+
+type operatorKind int
+
+const (
+    scalarValue operatorKind = iota
+    valueList
+    filterList
+    filterValue
+)
+
+// ValidateFilter checks every operator in filters, however deeply nested, against the operators Spire
+// is known to support ($and, $or, $not, $eq, $ne, $lt, $lte, $gt, $gte, $in, $nin, $regex and $like),
+// and that $and, $or, $in and $nin are given lists, so a typo such as "$ors" fails before the request
+// Validation is opt-in, see SpireClient.ValidateFilters, as a server may support further operators
+func ValidateFilter(filters map[string]interface{}) error {
+    if len(filters) == 0 {
+        return nil
+    }
+    jsonBytes, err := json.Marshal(filters)
+    if err != nil {
+        return fmt.Errorf("failed to marshal filter to JSON: %w", err)
+    }
+    var decoded map[string]interface{}
+    if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+        return fmt.Errorf("filter must be a JSON object: %w", err)
+    }
+    return validateClause(decoded, "filter")
+}
+
+// Validates the keys of one filter object, reporting errors at path, e.g. filter.$or[1].onHand
+func validateClause(clause map[string]interface{}, path string) error {
+    keys := make([]string, 0, len(clause))
+    for key := range clause {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    for _, key := range keys {
+        value, keyPath := clause[key], path+"."+key
+        if len(key) == 0 || key[0] != '$' {
+            if ops, ok := value.(map[string]interface{}); ok {
+                if err := validateClause(ops, keyPath); err != nil {
+                    return err
+                }
+            }
+            continue
+        }
+
+        kind, known := filterOperators[key]
+        if !known {
+            return fmt.Errorf("invalid filter: unknown operator %s at %s", key, path)
+        }
+        switch kind {
+        case valueList, filterList:
+            list, ok := value.([]interface{})
+            if !ok {
+                return fmt.Errorf("invalid filter: %s takes a list at %s", key, keyPath)
+            }
+            if kind == valueList {
+                continue
+            }
+            for i, item := range list {
+                sub, ok := item.(map[string]interface{})
+                if !ok {
+                    return fmt.Errorf("invalid filter: %s takes a list of filters at %s[%d]", key, keyPath, i)
+                }
+                if err := validateClause(sub, fmt.Sprintf("%s[%d]", keyPath, i)); err != nil {
+                    return err
+                }
+            }
+        case filterValue:
+            if sub, ok := value.(map[string]interface{}); ok {
+                if err := validateClause(sub, keyPath); err != nil {
+                    return err
+                }
+            }
+        }
+    }
+    return nil
+}
@@ -0,0 +1,145 @@
+package spireclient
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "regexp"
+    "testing"
+)
+
+func TestDefaultFiltersMerge(t *testing.T) {
+    client := NewSpireClient("http://spire.test", WithDefaultFilter("/customers", Filter{
+        "status": "A",
+        "$or": []interface{}{Filter{"type": "R"}, Filter{"type": "W"}},
+    }))
+
+    tests := []struct {
+        name string
+        endpoint string
+        filters Filter
+        opts FetchOptions
+        want string
+    }{
+        {"defaults only", "/customers", nil, FetchOptions{}, `{"$or":[{"type":"R"},{"type":"W"}],"status":"A"}`},
+        {"disjoint keys merged", "/customers", Filter{"name": "Acme"}, FetchOptions{}, `{"$or":[{"type":"R"},{"type":"W"}],"name":"Acme","status":"A"}`},
+        {"caller key wins", "/customers", Filter{"status": "I"}, FetchOptions{}, `{"$or":[{"type":"R"},{"type":"W"}],"status":"I"}`},
+        {"caller $or replaces the default's", "/customers", Filter{"$or": []interface{}{Filter{"type": "X"}}}, FetchOptions{}, `{"$or":[{"type":"X"}],"status":"A"}`},
+        {"other endpoint unaffected", "/vendors", Filter{"name": "Acme"}, FetchOptions{}, `{"name":"Acme"}`},
+        {"lookup ignores defaults", "/customers", Filter{"email": "a@b.c"}, FetchOptions{lookup: true}, `{"email":"a@b.c"}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            u, q, err := client.listURL(tt.endpoint, tt.filters, 10, tt.opts)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got := q.Get("filter"); got != tt.want {
+                t.Errorf("filter of %s = %s, want %s", u.Path, got, tt.want)
+            }
+        })
+    }
+
+    if got := client.DefaultFilters["/customers"]["status"]; got != "A" {
+        t.Errorf("default filter modified by merge: status = %v", got)
+    }
+}
+
+func TestGetRecordByFieldIgnoresDefaultFilters(t *testing.T) {
+    var filters []string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        filters = append(filters, r.URL.Query().Get("filter"))
+        w.Write([]byte(`{"records":[{"id":3,"email":"a@b.c","status":"I"}],"count":1}`))
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL, WithDefaultFilter("/customers", Filter{"status": "A"}))
+    if _, err := client.GetRecordByField(context.Background(), "/customers", "email", "a@b.c", SpireAgent{}); err != nil {
+        t.Fatal(err)
+    }
+    if len(filters) != 1 || filters[0] != `{"email":"a@b.c"}` {
+        t.Errorf("filters sent = %q, want only the lookup's own", filters)
+    }
+}
+
+func TestFilterBuilderBuild(t *testing.T) {
+    tests := []struct {
+        name string
+        builder *FilterBuilder
+        want string
+    }{
+        {"different fields merged", NewFilterBuilder().Equals("whse", "00").Between("onHand", 1, 100),
+            `{"onHand":{"$gte":1,"$lte":100},"whse":"00"}`},
+        {"operators on one field merged", NewFilterBuilder().GreaterThan("onHand", 1).LessThan("onHand", 100),
+            `{"onHand":{"$gt":1,"$lt":100}}`},
+        {"in", NewFilterBuilder().In("status", "O", "H"),
+            `{"status":{"$in":["O","H"]}}`},
+        {"or", NewFilterBuilder().Equals("whse", "00").Or(Equals("status", 0), Equals("status", 1)),
+            `{"$or":[{"status":0},{"status":1}],"whse":"00"}`},
+        {"repeated value falls back to $and", NewFilterBuilder().Equals("whse", "00").Equals("whse", "01"),
+            `{"$and":[{"whse":"00"},{"whse":"01"}]}`},
+        {"value and operator fall back to $and", NewFilterBuilder().Equals("onHand", 5).GreaterThan("onHand", 1),
+            `{"$and":[{"onHand":5},{"onHand":{"$gt":1}}]}`},
+        {"repeated operator falls back to $and", NewFilterBuilder().GreaterThan("onHand", 1).GreaterThan("onHand", 2),
+            `{"$and":[{"onHand":{"$gt":1}},{"onHand":{"$gt":2}}]}`},
+        {"repeated or falls back to $and", NewFilterBuilder().Or(Equals("a", 1), Equals("b", 2)).Or(Equals("c", 3), Equals("d", 4)),
+            `{"$and":[{"$or":[{"a":1},{"b":2}]},{"$or":[{"c":3},{"d":4}]}]}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := ConvertFilter(tt.builder.Build())
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got != tt.want {
+                t.Errorf("Build() = %s, want %s", got, tt.want)
+            }
+        })
+    }
+
+    if f := NewFilterBuilder().Build(); f != nil {
+        t.Errorf("empty Build() = %v, want nil", f)
+    }
+}
+
+func TestContainsAndStartsWithMatchLiterally(t *testing.T) {
+    tests := []struct {
+        name string
+        filter Filter
+        want string
+        matches []string
+        rejects []string
+    }{
+        {"contains", Contains("description", "(large) 1.5"), `(?i)\(large\) 1\.5`,
+            []string{"Widget (LARGE) 1.5in", "(large) 1.5"},
+            []string{"Widget large 1.5", "Widget (large) 105"}},
+        {"starts with", StartsWith("partNo", "A+B["), `(?i)^A\+B\[`,
+            []string{"a+b[1]", "A+B["},
+            []string{"AAB[1]", "xA+B["}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var pattern string
+            for _, clause := range tt.filter {
+                pattern, _ = clause.(map[string]interface{})["$regex"].(string)
+            }
+            if pattern != tt.want {
+                t.Fatalf("$regex = %q, want %q", pattern, tt.want)
+            }
+            re, err := regexp.Compile(pattern)
+            if err != nil {
+                t.Fatalf("$regex %q does not compile: %v", pattern, err)
+            }
+            for _, s := range tt.matches {
+                if !re.MatchString(s) {
+                    t.Errorf("%q does not match %q", pattern, s)
+                }
+            }
+            for _, s := range tt.rejects {
+                if re.MatchString(s) {
+                    t.Errorf("%q matches %q", pattern, s)
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,65 @@
+package spireclient
+
+import (
+    "context"
+    "time"
+)
+
+const (
+    glAccountsEndpoint = "/gl/accounts"
+    glTransactionsEndpoint = "/gl/transactions"
+    glTransactionDateField = "date"
+)
+
+// DateRange bounds a query on a date field, inclusive at both ends
+// A zero From or To leaves that side open
+type DateRange struct {
+    From time.Time
+    To time.Time
+}
+
+// Filter clause for the range on field, nil when both ends are open
+func (r DateRange) filter(field string) Filter {
+    ops := map[string]interface{}{}
+    if !r.From.IsZero() {
+        ops["$gte"] = r.From.Format(time.DateOnly)
+    }
+    if !r.To.IsZero() {
+        ops["$lte"] = r.To.Format(time.DateOnly)
+    }
+    if len(ops) == 0 {
+        return nil
+    }
+    return Filter{field: ops}
+}
+
+// ListGLAccounts gets ALL general ledger accounts matching filters, paginating like FetchSpireData
+func (c *SpireClient) ListGLAccounts(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, glAccountsEndpoint, filters, agent)
+}
+
+// ListGLTransactions gets ALL general ledger transactions dated within dates and matching filters,
+// along with the total count Spire reports
+// Use StreamGLTransactions for large ranges
+func (c *SpireClient) ListGLTransactions(ctx context.Context, dates DateRange, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, int, error) {
+    return c.FetchSpireDataWithCount(ctx, glTransactionsEndpoint, withDateRange(filters, dates), agent)
+}
+
+// StreamGLTransactions walks general ledger transactions like ListGLTransactions, passing each page
+// to fn together with the total count so progress can be checkpointed
+// Iteration stops at the first error returned by fn, which is returned unchanged
+func (c *SpireClient) StreamGLTransactions(ctx context.Context, dates DateRange, filters map[string]interface{}, agent SpireAgent, opts FetchOptions, fn func(page []map[string]interface{}, count int) error) error {
+    return streamPages[map[string]interface{}](ctx, c, glTransactionsEndpoint, withDateRange(filters, dates), agent, opts, fn)
+}
+
+// Adds the date range clause to filters, leaving filters untouched
+func withDateRange(filters map[string]interface{}, dates DateRange) map[string]interface{} {
+    clause := dates.filter(glTransactionDateField)
+    if clause == nil {
+        return filters
+    }
+    if len(filters) == 0 {
+        return clause
+    }
+    return NewFilterBuilder().add(filters).add(clause).Build()
+}
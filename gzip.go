@@ -0,0 +1,43 @@
+package spireclient
+
+import (
+    "compress/gzip"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+)
+
+// Replaces a gzip-encoded response body with its decompressed contents
+// The transport only decompresses on its own when it chose the Accept-Encoding, which the client now sets
+func decompressResponse(resp *http.Response) error {
+    if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+        return nil
+    }
+    if resp.StatusCode == http.StatusNoContent || resp.Request != nil && resp.Request.Method == http.MethodHead {
+        return nil
+    }
+
+    reader, err := gzip.NewReader(resp.Body)
+    if err != nil {
+        resp.Body.Close()
+        return fmt.Errorf("error decompressing response: %w", err)
+    }
+    resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+    resp.Header.Del("Content-Encoding")
+    resp.Header.Del("Content-Length")
+    resp.ContentLength = -1
+    resp.Uncompressed = true
+    return nil
+}
+
+// Decompressed response body that closes the underlying one
+type gzipBody struct {
+    *gzip.Reader
+    body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+    b.Reader.Close()
+    return b.body.Close()
+}
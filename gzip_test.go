@@ -0,0 +1,50 @@
+package spireclient
+
+import (
+    "compress/gzip"
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// Handler answering every request with status and body gzip-compressed
+func gzipHandler(t *testing.T, status int, body string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+            t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+        }
+        w.Header().Set("Content-Type", "application/json")
+        w.Header().Set("Content-Encoding", "gzip")
+        w.WriteHeader(status)
+        gz := gzip.NewWriter(w)
+        gz.Write([]byte(body))
+        gz.Close()
+    }
+}
+
+func TestGzipResponseDecodes(t *testing.T) {
+    server := httptest.NewServer(gzipHandler(t, http.StatusOK, `{"records":[{"partNo":"A-100"},{"partNo":"B-200"}],"count":2}`))
+    defer server.Close()
+
+    records, err := NewSpireClient(server.URL).FetchSpireData("/inventory/items", nil, SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(records) != 2 || records[0]["partNo"] != "A-100" || records[1]["partNo"] != "B-200" {
+        t.Errorf("got %v, want parts A-100 and B-200", records)
+    }
+}
+
+func TestGzipErrorBodyDecodes(t *testing.T) {
+    server := httptest.NewServer(gzipHandler(t, http.StatusBadRequest, `{"message":"unknown field orderQty"}`))
+    defer server.Close()
+
+    _, err := NewSpireClient(server.URL).SpireRequestContext(context.Background(), "/sales/orders", SpireAgent{}, http.MethodGet, nil)
+    var spireErr *SpireError
+    if !errors.As(err, &spireErr) || !strings.Contains(spireErr.Detail, "unknown field orderQty") {
+        t.Errorf("got %v, want a *SpireError with the decompressed message", err)
+    }
+}
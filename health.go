@@ -0,0 +1,83 @@
+package spireclient
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "time"
+)
+
+// PingResult separates the ways a Spire connection can be unhealthy
+type PingResult struct {
+    // A response was received, whatever its status
+    Reachable bool
+    // The server accepted the credentials, i.e. answered with neither 401 nor 403
+    Authenticated bool
+    // Zero when the server was unreachable
+    StatusCode int
+    Latency time.Duration
+    // *SpireError for any failure, with Status StatusConnectionError when unreachable, nil when healthy
+    Err error
+}
+
+// Healthy reports a reachable server that accepted the credentials and answered 200
+func (r PingResult) Healthy() bool {
+    return r.Reachable && r.Authenticated && r.StatusCode == http.StatusOK
+}
+
+// Ping checks connectivity and credentials with a GET of the root URL, reporting server unreachable,
+// bad credentials and server errors separately
+// Unlike ValidateSpireCredentials it never uses the validation cache
+func (c *SpireClient) Ping(ctx context.Context, agent SpireAgent) PingResult {
+    started := time.Now()
+    resp, err := c.do(ctx, "", agent, "GET", nil)
+    result := PingResult{Latency: time.Since(started)}
+    if err != nil {
+        result.Err = err
+        return result
+    }
+    defer resp.Body.Close()
+
+    result.Reachable = true
+    result.StatusCode = resp.StatusCode
+    result.Authenticated = resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(resp.Body)
+        result.Err = &SpireError{
+            StatusCode: resp.StatusCode,
+            Status: resp.Status,
+            Detail: agent.redact(string(body)),
+        }
+    }
+    return result
+}
+
+// CredentialsResult tells apart the reasons a credential check can fail
+type CredentialsResult struct {
+    // The server accepted the credentials with a 200
+    Valid bool
+    // A response was received, whatever its status
+    ServerReachable bool
+    // Zero when the server was unreachable, 200 when Valid
+    StatusCode int
+    // Why the credentials are not Valid, nil when they are
+    Err error
+}
+
+// ValidateCredentialsDetailed is ValidateSpireCredentialsContext reporting whether the server was
+// reachable and the status it answered with, instead of a single error
+func (c *SpireClient) ValidateCredentialsDetailed(ctx context.Context, agent SpireAgent) CredentialsResult {
+    err := c.ValidateSpireCredentialsContext(ctx, agent)
+    if err == nil {
+        return CredentialsResult{Valid: true, ServerReachable: true, StatusCode: http.StatusOK}
+    }
+
+    result := CredentialsResult{Err: err}
+    var spireErr *SpireError
+    if errors.As(err, &spireErr) && spireErr.StatusCode != 0 {
+        result.ServerReachable = true
+        result.StatusCode = spireErr.StatusCode
+    }
+    return result
+}
@@ -0,0 +1,29 @@
+package spireclient
+
+import (
+    "context"
+)
+
+// HistoryEntry is one change recorded in a record's history
+type HistoryEntry struct {
+    // When the change was made, the zero time if Spire didn't record it
+    Timestamp SpireTime `json:"timestamp"`
+    // Spire user who made the change
+    User string `json:"user"`
+    // Field changed, empty for changes to the record as a whole such as its creation
+    Field string `json:"field,omitempty"`
+    OldValue interface{} `json:"oldValue,omitempty"`
+    NewValue interface{} `json:"newValue,omitempty"`
+}
+
+// GetRecordHistory gets every change recorded for the record endpoint/{id}, from endpoint/{id}/history,
+// paginating like FetchSpireData
+// A missing record returns an error matching ErrNotFound
+func (c *SpireClient) GetRecordHistory(ctx context.Context, endpoint, id string, agent SpireAgent) ([]HistoryEntry, error) {
+    return FetchSpireDataTypedContext[HistoryEntry](ctx, c, recordPath(endpoint, id)+"/history", nil, agent)
+}
+
+// GetSalesOrderHistory gets every change recorded for the sales order with the given id, see GetRecordHistory
+func (c *SpireClient) GetSalesOrderHistory(ctx context.Context, id string, agent SpireAgent) ([]HistoryEntry, error) {
+    return c.GetRecordHistory(ctx, "/sales/orders", id, agent)
+}
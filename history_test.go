@@ -0,0 +1,36 @@
+package spireclient
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestGetSalesOrderHistoryParsesTimestamps(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path != "/sales/orders/12/history" {
+            t.Errorf("path = %s, want /sales/orders/12/history", r.URL.Path)
+        }
+        w.Write([]byte(`{"records":[
+            {"timestamp":"2024-01-15T09:30:00","user":"jdoe","field":"status","oldValue":"O","newValue":"C"},
+            {"timestamp":null,"user":"system"}
+        ],"count":2}`))
+    }))
+    defer server.Close()
+
+    history, err := NewSpireClient(server.URL).GetSalesOrderHistory(context.Background(), "12", SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(history) != 2 {
+        t.Fatalf("got %d entries, want 2", len(history))
+    }
+    if want := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC); !history[0].Timestamp.Equal(want) {
+        t.Errorf("Timestamp = %v, want %v", history[0].Timestamp, want)
+    }
+    if !history[1].Timestamp.IsZero() {
+        t.Errorf("null Timestamp = %v, want the zero time", history[1].Timestamp)
+    }
+}
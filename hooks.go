@@ -0,0 +1,79 @@
+package spireclient
+
+import (
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// RequestInfo describes a single HTTP request made to Spire
+// It deliberately carries no headers, so credentials never reach a hook
+type RequestInfo struct {
+    // Id sent in the request id header, empty when the request had none, see WithRequestID
+    RequestID string
+    Method string
+    // Full request URL including the query, e.g. the filter and paging parameters
+    URL string
+    // Zero when no response was received
+    StatusCode int
+    Duration time.Duration
+    // Zero for the first attempt, counting up with each retry
+    Attempt int
+    // Transport error when no response was received
+    Err error
+}
+
+// Metrics receives a measurement of every HTTP request made to Spire, e.g. to feed Prometheus
+// Implementations must be safe for concurrent use
+type Metrics interface {
+    // endpoint is the request path relative to RootURL and Company with numeric ids replaced by "{id}",
+    // e.g. "/sales/orders/{id}", keeping label cardinality low
+    // status is zero when no response was received
+    ObserveRequest(endpoint, method string, status int, duration time.Duration)
+}
+
+// Reports a finished HTTP attempt to OnRequest and Metrics, a no-op when neither is set
+func (c *SpireClient) observe(req *http.Request, resp *http.Response, err error, started time.Time, attempt int) {
+    if c.OnRequest == nil && c.Metrics == nil {
+        return
+    }
+
+    info := RequestInfo{
+        RequestID: req.Header.Get(c.requestIDHeader()),
+        Method: req.Method,
+        URL: req.URL.Redacted(),
+        Duration: time.Since(started),
+        Attempt: attempt,
+        Err: err,
+    }
+    if resp != nil {
+        info.StatusCode = resp.StatusCode
+    }
+    if c.Metrics != nil {
+        c.Metrics.ObserveRequest(c.metricsEndpoint(req.URL), info.Method, info.StatusCode, info.Duration)
+    }
+    if c.OnRequest != nil {
+        c.OnRequest(info)
+    }
+}
+
+// Path of u relative to RootURL and Company with every all-digit segment replaced by "{id}"
+func (c *SpireClient) metricsEndpoint(u *url.URL) string {
+    endpoint := u.Path
+    if root, err := url.Parse(c.companyURL()); err == nil {
+        endpoint = strings.TrimPrefix(endpoint, strings.TrimRight(root.Path, "/"))
+    }
+
+    segments := strings.Split(endpoint, "/")
+    for i, segment := range segments {
+        if segment != "" && strings.Trim(segment, "0123456789") == "" {
+            segments[i] = "{id}"
+        }
+    }
+    endpoint = strings.Join(segments, "/")
+    if endpoint == "" {
+        return "/"
+    }
+    return endpoint
+}
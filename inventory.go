@@ -0,0 +1,153 @@
+package spireclient
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "math"
+    "strconv"
+)
+
+const (
+    inventoryItemsEndpoint = "/inventory/items"
+    inventoryAdjustmentsEndpoint = "/inventory/adjustments"
+)
+
+// InventoryAdjustment is the body CreateInventoryAdjustment sends, e.g. after a physical count
+type InventoryAdjustment struct {
+    Reference string `json:"reference,omitempty"`
+    Items []InventoryAdjustmentLine `json:"items"`
+}
+
+// InventoryAdjustmentLine changes the stock of one part in one warehouse
+// Quantity is a decimal string, negative to remove stock
+type InventoryAdjustmentLine struct {
+    Whse string `json:"whse"`
+    PartNo string `json:"partNo"`
+    Quantity string `json:"quantity"`
+    Reason string `json:"reason,omitempty"`
+}
+
+// NewInventoryAdjustmentLine builds a line adding qty of partNo to warehouse whse, or removing it when negative
+func NewInventoryAdjustmentLine(whse, partNo string, qty float64, reason string) InventoryAdjustmentLine {
+    return InventoryAdjustmentLine{
+        Whse: whse,
+        PartNo: partNo,
+        Quantity: strconv.FormatFloat(qty, 'f', -1, 64),
+        Reason: reason,
+    }
+}
+
+// CreateInventoryAdjustment posts a stock adjustment, such as an InventoryAdjustment, and returns its id
+func (c *SpireClient) CreateInventoryAdjustment(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    return c.CreateRecordWithID(ctx, inventoryAdjustmentsEndpoint, agent, payload)
+}
+
+// GetInventoryItem returns the inventory record for a part number in a warehouse
+// No match returns an error matching ErrNotFound, more than one an error matching ErrMultipleMatches
+func (c *SpireClient) GetInventoryItem(ctx context.Context, whse, partNo string, agent SpireAgent) (map[string]interface{}, error) {
+    items, err := c.FetchSpireDataWithOptions(ctx, inventoryItemsEndpoint, Filter{"whse": whse, "partNo": partNo}, agent, FetchOptions{lookup: true})
+    if err != nil {
+        return nil, err
+    }
+
+    switch len(items) {
+    case 0:
+        return nil, fmt.Errorf("inventory item %s in warehouse %s: %w", partNo, whse, ErrNotFound)
+    case 1:
+        return items[0], nil
+    default:
+        return nil, fmt.Errorf("inventory item %s in warehouse %s: %d records: %w", partNo, whse, len(items), ErrMultipleMatches)
+    }
+}
+
+// GetInventoryQuantity returns the onHand quantity of a part number in a warehouse
+func (c *SpireClient) GetInventoryQuantity(ctx context.Context, whse, partNo string, agent SpireAgent) (float64, error) {
+    item, err := c.GetInventoryItem(ctx, whse, partNo, agent)
+    if err != nil {
+        return 0, err
+    }
+
+    onHand, err := toFloat(item["onHand"])
+    if err != nil {
+        return 0, fmt.Errorf("inventory item %s in warehouse %s: onHand: %w", partNo, whse, err)
+    }
+    return onHand, nil
+}
+
+// InventoryByPart is the stock of one part across every warehouse stocking it
+type InventoryByPart struct {
+    PartNo string
+    // One entry per warehouse, ordered by warehouse code
+    Warehouses []WarehouseStock
+    // Sum of OnHand over Warehouses
+    TotalOnHand float64
+}
+
+// WarehouseStock is the stock of a part in a single warehouse
+type WarehouseStock struct {
+    Whse string
+    OnHand float64
+    // Inventory record the quantity was read from
+    Record map[string]interface{}
+}
+
+// GetInventoryByPart returns the stock of partNo in every warehouse along with the consolidated total
+// A part with no inventory records returns an error matching ErrNotFound
+func (c *SpireClient) GetInventoryByPart(ctx context.Context, partNo string, agent SpireAgent) (InventoryByPart, error) {
+    items, err := c.FetchSpireDataWithOptions(ctx, inventoryItemsEndpoint, Filter{"partNo": partNo}, agent, FetchOptions{
+        Sort: []SortField{Ascending("whse")},
+        lookup: true,
+    })
+    if err != nil {
+        return InventoryByPart{}, err
+    }
+    if len(items) == 0 {
+        return InventoryByPart{}, fmt.Errorf("inventory item %s: %w", partNo, ErrNotFound)
+    }
+
+    result := InventoryByPart{PartNo: partNo, Warehouses: make([]WarehouseStock, 0, len(items))}
+    for _, item := range items {
+        whse, _ := item["whse"].(string)
+        onHand, err := toFloat(item["onHand"])
+        if err != nil {
+            return InventoryByPart{}, fmt.Errorf("inventory item %s in warehouse %s: onHand: %w", partNo, whse, err)
+        }
+        result.Warehouses = append(result.Warehouses, WarehouseStock{Whse: whse, OnHand: onHand, Record: item})
+        result.TotalOnHand += onHand
+    }
+    return result, nil
+}
+
+// SumOnHand totals the onHand quantity of inventory records, e.g. from a FetchSpireData of /inventory/items
+func SumOnHand(items []map[string]interface{}) (float64, error) {
+    var total float64
+    for i, item := range items {
+        onHand, err := toFloat(item["onHand"])
+        if err != nil {
+            return 0, fmt.Errorf("inventory record %d: onHand: %w", i, err)
+        }
+        total += onHand
+    }
+    return total, nil
+}
+
+// Converts a decoded JSON number, a json.Number, or a numeric string as Spire sends for some quantities, to float64
+// Only finite numbers are accepted, see parseDecimal
+func toFloat(v interface{}) (float64, error) {
+    switch n := v.(type) {
+    case float64:
+        if math.IsNaN(n) || math.IsInf(n, 0) {
+            return 0, fmt.Errorf("invalid number %v", n)
+        }
+        return n, nil
+    case json.Number:
+        return parseDecimal(n.String())
+    case string:
+        return parseDecimal(n)
+    case nil:
+        return 0, fmt.Errorf("missing value")
+    default:
+        return 0, fmt.Errorf("unexpected type %T", v)
+    }
+}
@@ -0,0 +1,41 @@
+package spireclient
+
+import (
+    "encoding/json"
+    "math"
+    "testing"
+)
+
+func TestToFloat(t *testing.T) {
+    valid := map[interface{}]float64{
+        2.5: 2.5,
+        json.Number("12.75"): 12.75,
+        "3": 3,
+        " -4.5 ": -4.5,
+    }
+    for in, want := range valid {
+        got, err := toFloat(in)
+        if err != nil || got != want {
+            t.Errorf("toFloat(%#v) = %v, %v, want %v", in, got, err, want)
+        }
+    }
+
+    invalid := []interface{}{
+        "3x", "5 boxes", "12abc", "", nil, true,
+        "NaN", "nan", "Inf", "+Inf", "-Infinity", "1e400", "0x1p-2", json.Number("NaN"), math.NaN(), math.Inf(1),
+    }
+    for _, in := range invalid {
+        if got, err := toFloat(in); err == nil {
+            t.Errorf("toFloat(%#v) = %v, want an error", in, got)
+        }
+    }
+}
+
+func TestSumOnHandRejectsInvalidNumbers(t *testing.T) {
+    for _, onHand := range []string{"3x", "NaN", "Inf"} {
+        items := []map[string]interface{}{{"onHand": "2"}, {"onHand": onHand}}
+        if total, err := SumOnHand(items); err == nil {
+            t.Errorf("onHand %q: got total %v, want an error", onHand, total)
+        }
+    }
+}
@@ -0,0 +1,32 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const (
+    salesInvoicesEndpoint = "/sales/invoices"
+    salesInvoiceItemsEndpoint = "/sales/invoice_items"
+)
+
+// GetSalesInvoice fetches a single sales invoice by its numeric id
+// A missing invoice returns an error matching ErrNotFound
+func (c *SpireClient) GetSalesInvoice(ctx context.Context, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.GetRecord(ctx, salesInvoicesEndpoint, id, agent)
+}
+
+// ListSalesInvoices gets ALL sales invoices matching filters, paginating like FetchSpireData
+func (c *SpireClient) ListSalesInvoices(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, salesInvoicesEndpoint, filters, agent)
+}
+
+// GetSalesInvoiceItems gets the line items of every invoice in invoiceNos, batched like GetOrderItems
+// by SpireClient.OrderItemsBatchSize
+func (c *SpireClient) GetSalesInvoiceItems(ctx context.Context, invoiceNos []string, agent SpireAgent) ([]map[string]interface{}, error) {
+    batchSize, err := c.itemsBatchSize()
+    if err != nil {
+        return nil, err
+    }
+
+    return c.fetchItemsIn(ctx, salesInvoiceItemsEndpoint, "invoiceNo", invoiceNos, batchSize, agent)
+}
@@ -0,0 +1,80 @@
+package spireclient
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "math/big"
+    "strconv"
+    "strings"
+)
+
+// json.Unmarshal, optionally keeping numbers in interface{} values as json.Number
+func unmarshalJSON(body []byte, v interface{}, useNumber bool) error {
+    if !useNumber {
+        return json.Unmarshal(body, v)
+    }
+
+    dec := json.NewDecoder(bytes.NewReader(body))
+    dec.UseNumber()
+    if err := dec.Decode(v); err != nil {
+        return err
+    }
+    if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+        return fmt.Errorf("invalid data after top-level value")
+    }
+    return nil
+}
+
+// ToRat converts a numeric record field to an exact rational, e.g. to sum monetary amounts without
+// floating point error
+// It accepts a json.Number from a client with UseNumber set, a decimal string as Spire sends for some
+// amounts, or a float64, which is converted from its shortest decimal representation
+func ToRat(v interface{}) (*big.Rat, error) {
+    var text string
+    switch n := v.(type) {
+    case json.Number:
+        text = n.String()
+    case string:
+        text = n
+    case float64:
+        text = strconv.FormatFloat(n, 'f', -1, 64)
+    case nil:
+        return nil, fmt.Errorf("missing value")
+    default:
+        return nil, fmt.Errorf("unexpected type %T", v)
+    }
+
+    r, ok := new(big.Rat).SetString(text)
+    if !ok {
+        return nil, fmt.Errorf("invalid number %q", text)
+    }
+    return r, nil
+}
+
+// SumRat totals the field of every record exactly, e.g. the amounts of a list of invoices
+func SumRat(records []map[string]interface{}, field string) (*big.Rat, error) {
+    total := new(big.Rat)
+    for i, record := range records {
+        r, err := ToRat(record[field])
+        if err != nil {
+            return nil, fmt.Errorf("record %d: %s: %w", i, field, err)
+        }
+        total.Add(total, r)
+    }
+    return total, nil
+}
+
+// Parses a decimal string as Spire sends for some quantities and amounts, rejecting trailing text as well as
+// the hex notation and the non-finite NaN and Inf that strconv.ParseFloat also accepts
+func parseDecimal(s string) (float64, error) {
+    text := strings.TrimSpace(s)
+    f, err := strconv.ParseFloat(text, 64)
+    if err != nil || strings.ContainsAny(text, "xX") || math.IsNaN(f) || math.IsInf(f, 0) {
+        return 0, fmt.Errorf("invalid number %q", s)
+    }
+    return f, nil
+}
@@ -0,0 +1,280 @@
+package spireclient
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "fmt"
+    "net/http"
+    "net/url"
+    "time"
+)
+
+// Option configures a SpireClient built by NewSpireClient
+// Options are applied in order, so WithHTTPClient should come before options that adjust the client
+type Option func(*SpireClient)
+
+// WithTimeout sets the timeout of each individual HTTP request, including each page and retry
+// attempt of a FetchSpireData call, rather than of the whole pagination loop
+// Use a context deadline to bound an entire FetchSpireData call
+func WithTimeout(timeout time.Duration) Option {
+    return func(c *SpireClient) {
+        httpClient := *c.HTTPClient
+        httpClient.Timeout = timeout
+        c.HTTPClient = &httpClient
+    }
+}
+
+// WithHTTPClient makes the client send requests through httpClient, e.g. to share a tuned transport
+// or plug in a custom RoundTripper; nil keeps the default client
+// Later options such as WithTimeout adjust a copy, leaving httpClient itself untouched
+func WithHTTPClient(httpClient *http.Client) Option {
+    return func(c *SpireClient) {
+        if httpClient == nil {
+            httpClient = &http.Client{Timeout: defaultTimeout}
+        }
+        c.HTTPClient = httpClient
+    }
+}
+
+// WithCompany addresses every relative endpoint to company and requires it to be non-empty,
+// see SpireClient.Company
+func WithCompany(company string) Option {
+    return func(c *SpireClient) {
+        c.Company = company
+        c.RequireCompany = true
+    }
+}
+
+// WithDefaultFilter merges filter into every fetch of endpoint, see SpireClient.DefaultFilters
+func WithDefaultFilter(endpoint string, filter Filter) Option {
+    return func(c *SpireClient) {
+        if c.DefaultFilters == nil {
+            c.DefaultFilters = make(map[string]Filter)
+        }
+        c.DefaultFilters[endpoint] = filter
+    }
+}
+
+// WithFilterValidation checks every filter with ValidateFilter before it is sent, see SpireClient.ValidateFilters
+func WithFilterValidation() Option {
+    return func(c *SpireClient) {
+        c.ValidateFilters = true
+    }
+}
+
+// WithSalesOrderValidation checks sales order payloads before they are sent, see SpireClient.ValidateSalesOrders
+func WithSalesOrderValidation() Option {
+    return func(c *SpireClient) {
+        c.ValidateSalesOrders = true
+    }
+}
+
+// WithPageLimit sets the records requested per page by FetchSpireData, see SpireClient.PageLimit
+func WithPageLimit(limit int) Option {
+    return func(c *SpireClient) {
+        c.PageLimit = limit
+    }
+}
+
+// WithMaxPages caps the pages a single FetchSpireData call may request, see SpireClient.MaxPages
+func WithMaxPages(maxPages int) Option {
+    return func(c *SpireClient) {
+        c.MaxPages = maxPages
+    }
+}
+
+// WithRequestIDs sends a request id with every request in the header name, a random one unless set with
+// WithRequestID; an empty name keeps X-Request-ID
+func WithRequestIDs(header string) Option {
+    return func(c *SpireClient) {
+        c.RequestIDHeader = header
+        c.GenerateRequestIDs = true
+    }
+}
+
+// WithUserAgent identifies the integration to the Spire server with the given User-Agent header
+func WithUserAgent(userAgent string) Option {
+    return func(c *SpireClient) {
+        c.UserAgent = userAgent
+    }
+}
+
+// WithRetries retries transient failures up to maxRetries times, starting at backoff and doubling
+// See SpireClient.MaxRetries for which requests are retried
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+    return func(c *SpireClient) {
+        c.MaxRetries = maxRetries
+        c.RetryBackoff = backoff
+    }
+}
+
+// WithRateLimit sends at most rps requests per second, allowing bursts of up to burst, see SpireClient.RateLimit
+func WithRateLimit(rps float64, burst int) Option {
+    return func(c *SpireClient) {
+        c.RateLimit = rps
+        c.RateBurst = burst
+    }
+}
+
+// WithRetryJitter sets how retry delays are randomised, e.g. JitterNone for deterministic tests
+func WithRetryJitter(jitter Jitter) Option {
+    return func(c *SpireClient) {
+        c.RetryJitter = jitter
+    }
+}
+
+// WithCircuitBreaker fails requests fast with ErrCircuitOpen for cooldown once threshold consecutive
+// failures occur within window, see SpireClient.BreakerThreshold
+func WithCircuitBreaker(threshold int, window, cooldown time.Duration) Option {
+    return func(c *SpireClient) {
+        c.BreakerThreshold = threshold
+        c.BreakerWindow = window
+        c.BreakerCooldown = cooldown
+    }
+}
+
+// WithValidationCache remembers a successful ValidateSpireCredentials for ttl, see SpireClient.ValidationTTL
+func WithValidationCache(ttl time.Duration) Option {
+    return func(c *SpireClient) {
+        c.ValidationTTL = ttl
+    }
+}
+
+// WithRequestHook calls hook after every HTTP request, see SpireClient.OnRequest
+func WithRequestHook(hook func(RequestInfo)) Option {
+    return func(c *SpireClient) {
+        c.OnRequest = hook
+    }
+}
+
+// WithUseNumber decodes numbers in records as json.Number, see SpireClient.UseNumber
+func WithUseNumber() Option {
+    return func(c *SpireClient) {
+        c.UseNumber = true
+    }
+}
+
+// WithMetrics reports every HTTP request to metrics, see SpireClient.Metrics
+func WithMetrics(metrics Metrics) Option {
+    return func(c *SpireClient) {
+        c.Metrics = metrics
+    }
+}
+
+// WithPreferMinimal asks the server not to echo records back from creates and updates, see SpireClient.PreferMinimal
+func WithPreferMinimal() Option {
+    return func(c *SpireClient) {
+        c.PreferMinimal = true
+    }
+}
+
+// WithDryRun logs mutating requests instead of sending them, see SpireClient.DryRun
+func WithDryRun() Option {
+    return func(c *SpireClient) {
+        c.DryRun = true
+    }
+}
+
+// WithAutoReauth turns renewing an expired session on a 401 on or off, see SpireClient.AutoReauth
+func WithAutoReauth(enabled bool) Option {
+    return func(c *SpireClient) {
+        c.AutoReauth = enabled
+    }
+}
+
+// WithHeader adds a header sent with every request, see SpireClient.Headers
+func WithHeader(key, value string) Option {
+    return func(c *SpireClient) {
+        if c.Headers == nil {
+            c.Headers = make(http.Header)
+        }
+        c.Headers.Add(key, value)
+    }
+}
+
+// WithResponseCache reuses FetchSpireData results for ttl, keeping at most size of them
+// See SpireClient.CacheTTL
+func WithResponseCache(ttl time.Duration, size int) Option {
+    return func(c *SpireClient) {
+        c.CacheTTL = ttl
+        c.CacheSize = size
+    }
+}
+
+// WithTLSConfig makes the client use tlsConfig for HTTPS connections, e.g. to present a client certificate
+// Like the other TLS options it configures a copy of the client's *http.Transport, and has no effect
+// when WithHTTPClient supplied a client with a different RoundTripper
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+    return func(c *SpireClient) {
+        withTransport(c, func(t *http.Transport) {
+            t.TLSClientConfig = tlsConfig.Clone()
+        })
+    }
+}
+
+// WithRootCAs trusts the certificate authorities in pool instead of the system trust store,
+// e.g. for a staging server with a certificate from a private CA
+func WithRootCAs(pool *x509.CertPool) Option {
+    return func(c *SpireClient) {
+        withTransport(c, func(t *http.Transport) {
+            t.TLSClientConfig = tlsConfigOf(t)
+            t.TLSClientConfig.RootCAs = pool
+        })
+    }
+}
+
+// WithInsecureSkipVerify accepts any server certificate, including self-signed and expired ones
+// This exposes credentials to anyone able to intercept the connection: use it only against
+// development servers, and prefer WithRootCAs wherever possible
+func WithInsecureSkipVerify() Option {
+    return func(c *SpireClient) {
+        withTransport(c, func(t *http.Transport) {
+            t.TLSClientConfig = tlsConfigOf(t)
+            t.TLSClientConfig.InsecureSkipVerify = true
+        })
+    }
+}
+
+// WithProxy sends every request through the HTTP proxy at proxyURL, e.g. "http://proxy.corp:3128"
+// Without it the default transport honours the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+// An invalid proxyURL makes every request fail with the parse error
+func WithProxy(proxyURL string) Option {
+    return func(c *SpireClient) {
+        proxy, err := url.Parse(proxyURL)
+        withTransport(c, func(t *http.Transport) {
+            if err != nil {
+                t.Proxy = func(*http.Request) (*url.URL, error) {
+                    return nil, fmt.Errorf("invalid proxy URL: %w", err)
+                }
+                return
+            }
+            t.Proxy = http.ProxyURL(proxy)
+        })
+    }
+}
+
+// Applies fn to a copy of the client's transport, leaving a transport shared through WithHTTPClient untouched
+func withTransport(c *SpireClient, fn func(*http.Transport)) {
+    var transport *http.Transport
+    switch base := c.HTTPClient.Transport.(type) {
+    case nil:
+        transport = http.DefaultTransport.(*http.Transport).Clone()
+    case *http.Transport:
+        transport = base.Clone()
+    default:
+        return
+    }
+    fn(transport)
+
+    httpClient := *c.HTTPClient
+    httpClient.Transport = transport
+    c.HTTPClient = &httpClient
+}
+
+// Copy of the transport's TLS config to modify, or a new one
+func tlsConfigOf(t *http.Transport) *tls.Config {
+    if t.TLSClientConfig == nil {
+        return &tls.Config{}
+    }
+    return t.TLSClientConfig.Clone()
+}
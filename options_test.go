@@ -0,0 +1,39 @@
+package spireclient
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestWithProxyRoutesRequestsThroughProxy(t *testing.T) {
+    var proxied []string
+    proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        // A proxy receives the absolute URL of the target rather than just its path
+        proxied = append(proxied, r.URL.String())
+        w.Write([]byte(`{"records":[{"id":1}],"count":1}`))
+    }))
+    defer proxy.Close()
+
+    client := NewSpireClient("http://spire.invalid/api/v2", WithProxy(proxy.URL))
+    records, err := client.FetchSpireData("/sales/orders", nil, SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(records) != 1 {
+        t.Errorf("got %d records, want the 1 the proxy answered with", len(records))
+    }
+    if len(proxied) != 1 || proxied[0] != "http://spire.invalid/api/v2/sales/orders?limit=1000" {
+        t.Errorf("proxy received %q, want the request for spire.invalid", proxied)
+    }
+}
+
+func TestWithProxyInvalidURL(t *testing.T) {
+    client := NewSpireClient("http://spire.invalid/api/v2", WithProxy("://no-scheme"))
+    _, err := client.SpireRequestContext(context.Background(), "/sales/orders", SpireAgent{}, http.MethodGet, nil)
+    if err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+        t.Errorf("got %v, want the invalid proxy URL reported", err)
+    }
+}
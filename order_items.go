@@ -0,0 +1,177 @@
+package spireclient
+
+import (
+    "context"
+    "fmt"
+    "sort"
+)
+
+const salesItemsEndpoint = "/sales/items"
+
+// Order numbers per filter when SpireClient.OrderItemsBatchSize is unset
+const defaultOrderItemsBatchSize = 100
+
+// OrderDetails identifies a sales order whose line items are wanted
+type OrderDetails struct {
+    OrderNo string
+}
+
+// GetOrderItems gets the line items of every order in orders from /sales/items
+// Orders are queried in batches of SpireClient.OrderItemsBatchSize order numbers so the filter stays
+// within Spire's URL length limit, and items appearing in more than one batch are returned once
+// If a batch fails, the items of the batches before it are returned along with the error
+func (c *SpireClient) GetOrderItems(ctx context.Context, orders map[string]OrderDetails, agent SpireAgent) ([]map[string]interface{}, error) {
+    batchSize, err := c.itemsBatchSize()
+    if err != nil {
+        return nil, err
+    }
+
+    orderNos := make([]string, 0, len(orders))
+    for _, order := range orders {
+        orderNos = append(orderNos, order.OrderNo)
+    }
+    return c.fetchItemsIn(ctx, salesItemsEndpoint, "orderNo", orderNos, batchSize, agent)
+}
+
+// GetOrderItemsByOrder is GetOrderItems grouping the items by the orderNo field of each item
+// Every requested order has an entry, empty when the order has no items, and each order's items keep
+// their line sequence; items without an orderNo are left out, and if a batch fails, the items grouped
+// so far are returned along with the error
+func (c *SpireClient) GetOrderItemsByOrder(ctx context.Context, orders map[string]OrderDetails, agent SpireAgent) (map[string][]map[string]interface{}, error) {
+    items, err := c.GetOrderItems(ctx, orders, agent)
+
+    grouped := make(map[string][]map[string]interface{}, len(orders))
+    for _, order := range orders {
+        if order.OrderNo != "" {
+            grouped[order.OrderNo] = []map[string]interface{}{}
+        }
+    }
+    for _, item := range items {
+        orderNo, ok := item["orderNo"].(string)
+        if !ok || orderNo == "" {
+            continue
+        }
+        grouped[orderNo] = append(grouped[orderNo], item)
+    }
+    return grouped, err
+}
+
+// GetItemsByPurchaseNo gets the line items of every sales order placed under the customer PO number
+// purchaseNo, i.e. whose customerPO field equals it, as used to match drop-ship orders
+// No matching order returns an empty slice and no error
+func (c *SpireClient) GetItemsByPurchaseNo(ctx context.Context, purchaseNo string, agent SpireAgent) ([]map[string]interface{}, error) {
+    orders, err := c.FetchSpireDataWithOptions(ctx, "/sales/orders", Equals("customerPO", purchaseNo), agent, FetchOptions{
+        Fields: []string{"orderNo"},
+        lookup: true,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("error finding sales orders for customer PO %s: %w", purchaseNo, err)
+    }
+
+    batchSize, err := c.itemsBatchSize()
+    if err != nil {
+        return nil, err
+    }
+    orderNos := make([]string, 0, len(orders))
+    for _, order := range orders {
+        if orderNo, ok := order["orderNo"].(string); ok {
+            orderNos = append(orderNos, orderNo)
+        }
+    }
+    items, err := c.fetchItemsIn(ctx, salesItemsEndpoint, "orderNo", orderNos, batchSize, agent)
+    if items == nil && err == nil {
+        items = []map[string]interface{}{}
+    }
+    return items, err
+}
+
+// GetSalesOrderItems gets the line items of the single sales order orderNo
+// An order without items returns an empty slice and no error
+func (c *SpireClient) GetSalesOrderItems(ctx context.Context, orderNo string, agent SpireAgent) ([]map[string]interface{}, error) {
+    items, err := c.FetchSpireDataWithOptions(ctx, salesItemsEndpoint, Equals("orderNo", orderNo), agent, FetchOptions{lookup: true})
+    if err != nil {
+        return items, err
+    }
+    if items == nil {
+        items = []map[string]interface{}{}
+    }
+    return items, nil
+}
+
+// Fetches the records of endpoint whose field is any of keys, querying batchSize keys at a time
+// and returning records that appear in more than one batch once, by id
+// Keys are de-duplicated and queried in sorted order, and the records are sorted by field, then
+// line sequence, then id, so the result is the same however the keys were ordered
+// When a batch fails, the records of the batches before it are returned with the error
+func (c *SpireClient) fetchItemsIn(ctx context.Context, endpoint, field string, keyList []string, batchSize int, agent SpireAgent) ([]map[string]interface{}, error) {
+    seenKeys := make(map[string]bool, len(keyList))
+    var sortedKeys []string
+    for _, key := range keyList {
+        if key == "" || seenKeys[key] {
+            continue
+        }
+        seenKeys[key] = true
+        sortedKeys = append(sortedKeys, key)
+    }
+    sort.Strings(sortedKeys)
+    keys := make([]interface{}, len(sortedKeys))
+    for i, key := range sortedKeys {
+        keys[i] = key
+    }
+
+    var items []map[string]interface{}
+    seenItems := make(map[interface{}]bool)
+    for start := 0; start < len(keys); start += batchSize {
+        end := start + batchSize
+        if end > len(keys) {
+            end = len(keys)
+        }
+
+        batch, err := c.FetchSpireDataWithOptions(ctx, endpoint, In(field, keys[start:end]...), agent, FetchOptions{lookup: true})
+        if err != nil {
+            sortItems(items, field)
+            return items, fmt.Errorf("error fetching items for %s %d-%d of %d: %w", field, start+1, end, len(keys), err)
+        }
+        for _, item := range batch {
+            if id, ok := item["id"]; ok {
+                if seenItems[id] {
+                    continue
+                }
+                seenItems[id] = true
+            }
+            items = append(items, item)
+        }
+    }
+    sortItems(items, field)
+    return items, nil
+}
+
+// Resolves SpireClient.OrderItemsBatchSize, falling back to the default when unset
+func (c *SpireClient) itemsBatchSize() (int, error) {
+    batchSize := c.OrderItemsBatchSize
+    if batchSize == 0 {
+        batchSize = defaultOrderItemsBatchSize
+    }
+    if batchSize < 0 {
+        return 0, fmt.Errorf("invalid order items batch size %d: must be greater than 0", batchSize)
+    }
+    return batchSize, nil
+}
+
+// Orders items by field, then by their sequence on the document, then by id
+func sortItems(items []map[string]interface{}, field string) {
+    sort.SliceStable(items, func(i, j int) bool {
+        a, b := fmt.Sprint(items[i][field]), fmt.Sprint(items[j][field])
+        if a != b {
+            return a < b
+        }
+        for _, key := range []string{"sequence", "id"} {
+            x, xErr := toFloat(items[i][key])
+            y, yErr := toFloat(items[j][key])
+            if xErr == nil && yErr == nil && x != y {
+                return x < y
+            }
+        }
+        return false
+    })
+}
@@ -0,0 +1,89 @@
+package spireclient
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestGetOrderItemsStableOrder(t *testing.T) {
+    // Items in no particular order, with a sequence that sorts differently as text and as a number
+    all := []map[string]interface{}{
+        {"id": 6, "orderNo": "C3", "sequence": 2},
+        {"id": 3, "orderNo": "A1", "sequence": 10},
+        {"id": 4, "orderNo": "B2", "sequence": 1},
+        {"id": 2, "orderNo": "A1", "sequence": 2},
+        {"id": 5, "orderNo": "C3", "sequence": 1},
+        {"id": 1, "orderNo": "A1", "sequence": 1},
+    }
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var filter struct {
+            OrderNo struct {
+                In []string `json:"$in"`
+            } `json:"orderNo"`
+        }
+        if err := json.Unmarshal([]byte(r.URL.Query().Get("filter")), &filter); err != nil {
+            t.Errorf("filter %q: %v", r.URL.Query().Get("filter"), err)
+        }
+        wanted := map[string]bool{}
+        for _, orderNo := range filter.OrderNo.In {
+            wanted[orderNo] = true
+        }
+        // Item 1 is returned by every batch, as overlapping filters can do
+        records := []map[string]interface{}{all[5]}
+        for _, item := range all {
+            if wanted[item["orderNo"].(string)] {
+                records = append(records, item)
+            }
+        }
+        json.NewEncoder(w).Encode(map[string]interface{}{"records": records, "count": len(records)})
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL)
+    client.OrderItemsBatchSize = 2
+    orders := map[string]OrderDetails{"x": {OrderNo: "C3"}, "y": {OrderNo: "A1"}, "z": {OrderNo: "B2"}, "w": {OrderNo: "A1"}}
+    for run := 0; run < 5; run++ {
+        items, err := client.GetOrderItems(context.Background(), orders, SpireAgent{})
+        if err != nil {
+            t.Fatal(err)
+        }
+        var got []string
+        for _, item := range items {
+            got = append(got, fmt.Sprintf("%v/%v", item["orderNo"], item["sequence"]))
+        }
+        if want := "[A1/1 A1/2 A1/10 B2/1 C3/1 C3/2]"; fmt.Sprint(got) != want {
+            t.Fatalf("run %d: items %v, want %s", run, got, want)
+        }
+    }
+}
+
+func TestGetOrderItemsByOrderSkipsItemsWithoutOrderNo(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte(`{"records":[
+            {"id":1,"orderNo":"A1","sequence":1},
+            {"id":2,"sequence":1},
+            {"id":3,"orderNo":null,"sequence":2},
+            {"id":4,"orderNo":"A1","sequence":2}
+        ],"count":4}`))
+    }))
+    defer server.Close()
+
+    orders := map[string]OrderDetails{"a": {OrderNo: "A1"}, "b": {OrderNo: "B2"}}
+    grouped, err := NewSpireClient(server.URL).GetOrderItemsByOrder(context.Background(), orders, SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(grouped) != 2 {
+        t.Errorf("got groups for %d orders, want 2: %v", len(grouped), grouped)
+    }
+    if _, ok := grouped["<nil>"]; ok {
+        t.Error("items without an orderNo were grouped under \"<nil>\"")
+    }
+    if len(grouped["A1"]) != 2 || len(grouped["B2"]) != 0 {
+        t.Errorf("A1 has %d items and B2 %d, want 2 and 0", len(grouped["A1"]), len(grouped["B2"]))
+    }
+}
@@ -0,0 +1,29 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const paymentsEndpoint = "/ar/payments"
+
+// Payment is a customer payment, for CreatePayment or typed fetches of /ar/payments
+// Amount is a decimal string, as Spire sends it, so no precision is lost
+type Payment struct {
+    ID int `json:"id,omitempty"`
+    CustomerNo string `json:"customerNo"`
+    Amount string `json:"amount"`
+    // Payment method code, e.g. cash, cheque or card as configured in Spire
+    Method string `json:"method"`
+    // Formatted YYYY-MM-DD
+    Date string `json:"date"`
+}
+
+// CreatePayment records a customer payment, such as a Payment, and returns its id, taken from the Location header
+func (c *SpireClient) CreatePayment(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    return c.CreateRecordWithID(ctx, paymentsEndpoint, agent, payload)
+}
+
+// ListPayments gets ALL payments matching filters, paginating like FetchSpireData
+func (c *SpireClient) ListPayments(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, paymentsEndpoint, filters, agent)
+}
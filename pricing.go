@@ -0,0 +1,18 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const priceMatrixEndpoint = "/inventory/price_matrix"
+
+// ListPriceLists gets ALL price matrix entries matching filters, paginating like FetchSpireData
+// Each entry prices a part for a customer or price level, with quantity breaks where the entry has them
+func (c *SpireClient) ListPriceLists(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, priceMatrixEndpoint, filters, agent)
+}
+
+// GetItemPrices gets every price matrix entry for partNo, i.e. its price for each customer and price level
+func (c *SpireClient) GetItemPrices(ctx context.Context, partNo string, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.ListPriceLists(ctx, Equals("partNo", partNo), agent)
+}
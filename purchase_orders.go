@@ -0,0 +1,45 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const purchaseOrdersEndpoint = "/purchasing/orders"
+
+// CreatePurchaseOrder sends a POST request to Spire to create a new purchase order
+// The payload should be the fully prepared purchase order body structure
+func (c *SpireClient) CreatePurchaseOrder(ctx context.Context, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.CreateRecord(ctx, purchaseOrdersEndpoint, agent, payload)
+}
+
+// CreatePurchaseOrderWithID creates a purchase order and returns its id, taken from the Location header
+func (c *SpireClient) CreatePurchaseOrderWithID(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    return c.CreateRecordWithID(ctx, purchaseOrdersEndpoint, agent, payload)
+}
+
+// GetPurchaseOrder fetches a single purchase order by its numeric id
+// A missing order returns an error matching ErrNotFound
+func (c *SpireClient) GetPurchaseOrder(ctx context.Context, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.GetRecord(ctx, purchaseOrdersEndpoint, id, agent)
+}
+
+// UpdatePurchaseOrder replaces the purchase order with the given id using a PUT of the full order
+func (c *SpireClient) UpdatePurchaseOrder(ctx context.Context, id string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.UpdateRecord(ctx, purchaseOrdersEndpoint, id, agent, payload)
+}
+
+// DeletePurchaseOrder deletes the purchase order with the given id
+func (c *SpireClient) DeletePurchaseOrder(ctx context.Context, id string, agent SpireAgent) error {
+    return c.DeleteRecord(ctx, purchaseOrdersEndpoint, id, agent)
+}
+
+// DeletePurchaseOrders deletes every order in ids, continuing past individual failures
+// The result reports each id's outcome; the error joins all failures and is nil when every delete succeeded
+func (c *SpireClient) DeletePurchaseOrders(ctx context.Context, agent SpireAgent, ids []string) (DeleteResult, error) {
+    return c.DeletePurchaseOrdersWithOptions(ctx, agent, ids, DeleteOptions{})
+}
+
+// DeletePurchaseOrdersWithOptions is DeletePurchaseOrders customised by opts, e.g. to delete in parallel
+func (c *SpireClient) DeletePurchaseOrdersWithOptions(ctx context.Context, agent SpireAgent, ids []string, opts DeleteOptions) (DeleteResult, error) {
+    return c.deleteBatch(ctx, purchaseOrdersEndpoint, ids, agent, opts)
+}
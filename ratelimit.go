@@ -0,0 +1,63 @@
+package spireclient
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+// Token bucket shared by every request of a client, see SpireClient.RateLimit
+type rateLimiter struct {
+    mu sync.Mutex
+    tokens float64
+    last time.Time
+}
+
+// Takes a token, returning how long the caller must wait before it is available
+// Tokens may go negative, queueing later callers behind earlier ones
+func (l *rateLimiter) reserve(rps float64, burst int) time.Duration {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    now := time.Now()
+    if l.last.IsZero() {
+        l.tokens = float64(burst)
+    } else {
+        l.tokens = min(float64(burst), l.tokens+now.Sub(l.last).Seconds()*rps)
+    }
+    l.last = now
+
+    l.tokens--
+    if l.tokens >= 0 {
+        return 0
+    }
+    return time.Duration(-l.tokens / rps * float64(time.Second))
+}
+
+// Returns a token reserved by a caller that gave up waiting
+func (l *rateLimiter) cancel() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.tokens++
+}
+
+// Waits until the client's rate limit allows another request, or ctx is done
+func (c *SpireClient) waitRate(ctx context.Context) error {
+    if c.RateLimit <= 0 {
+        return nil
+    }
+    burst := c.RateBurst
+    if burst < 1 {
+        burst = 1
+    }
+
+    delay := c.limiter.reserve(c.RateLimit, burst)
+    if delay == 0 {
+        return nil
+    }
+    if err := sleepContext(ctx, delay); err != nil {
+        c.limiter.cancel()
+        return err
+    }
+    return nil
+}
@@ -0,0 +1,200 @@
+package spireclient
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "path"
+    "reflect"
+    "strconv"
+    "strings"
+)
+
+// CreateRecord POSTs payload to any collection endpoint, e.g. "/customers"
+func (c *SpireClient) CreateRecord(ctx context.Context, endpoint string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.SpireRequestContext(ctx, endpoint, agent, "POST", payload)
+}
+
+// CreateRecordWithID POSTs payload to any collection endpoint and returns the id of the created record
+// from the Location header Spire sends with the 201
+func (c *SpireClient) CreateRecordWithID(ctx context.Context, endpoint string, agent SpireAgent, payload interface{}) (string, error) {
+    raw, err := c.SpireRequestRaw(ctx, endpoint, agent, "POST", payload)
+    if err != nil {
+        return "", err
+    }
+    return locationID(raw.Header.Get("Location"))
+}
+
+// GetRecord fetches the single record endpoint/{id}
+// A missing record returns an error matching ErrNotFound
+func (c *SpireClient) GetRecord(ctx context.Context, endpoint, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.SpireRequestObject(ctx, recordPath(endpoint, id), agent, "GET", nil)
+}
+
+// GetRecordByField fetches the one record of endpoint whose field equals value, e.g. a customer by email
+// No match returns an error matching ErrNotFound, more than one an error matching ErrMultipleMatches
+// that reports how many matched; only two records are transferred either way
+func (c *SpireClient) GetRecordByField(ctx context.Context, endpoint, field string, value interface{}, agent SpireAgent) (map[string]interface{}, error) {
+    page, err := c.FetchPage(ctx, endpoint, Equals(field, value), agent, FetchOptions{Limit: 2, lookup: true})
+    if err != nil {
+        return nil, err
+    }
+
+    switch {
+    case len(page.Records) == 0:
+        return nil, fmt.Errorf("%s with %s=%v: %w", endpoint, field, value, ErrNotFound)
+    case len(page.Records) > 1 || page.Count > 1:
+        return nil, fmt.Errorf("%s with %s=%v: %d records: %w", endpoint, field, value, max(page.Count, len(page.Records)), ErrMultipleMatches)
+    }
+    return page.Records[0], nil
+}
+
+// UpdateRecord replaces the record endpoint/{id} with a PUT of payload
+// The updated record Spire returns is the single record of the response
+func (c *SpireClient) UpdateRecord(ctx context.Context, endpoint, id string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.SpireRequestContext(ctx, recordPath(endpoint, id), agent, "PUT", payload)
+}
+
+// PatchRecord partially updates the record endpoint/{id} with a PATCH carrying only the changed fields,
+// leaving every other field as it is on the server
+// The updated record Spire returns is the single record of the response
+func (c *SpireClient) PatchRecord(ctx context.Context, endpoint, id string, agent SpireAgent, fields interface{}) (SpireResponse, error) {
+    return c.SpireRequestContext(ctx, recordPath(endpoint, id), agent, "PATCH", fields)
+}
+
+// ReplaceRecord replaces the record endpoint/{id} with a PUT of payload as is, so any field payload
+// leaves out is blanked; use MergeRecord to change only some fields
+func (c *SpireClient) ReplaceRecord(ctx context.Context, endpoint, id string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.UpdateRecord(ctx, endpoint, id, agent, payload)
+}
+
+// MergeRecord reads the record endpoint/{id}, overlays fields by top-level key and PUTs the result back,
+// so fields not in fields keep their current values
+// When the read carried an ETag the write is sent with If-Match; otherwise the record is read again just
+// before the write. Either way a record changed in between fails with an error matching ErrRecordChanged
+func (c *SpireClient) MergeRecord(ctx context.Context, endpoint, id string, agent SpireAgent, fields map[string]interface{}) (SpireResponse, error) {
+    current, etag, err := c.readRecord(ctx, endpoint, id, agent)
+    if err != nil {
+        return SpireResponse{}, err
+    }
+
+    merged := make(map[string]interface{}, len(current)+len(fields))
+    for key, value := range current {
+        merged[key] = value
+    }
+    for key, value := range fields {
+        merged[key] = value
+    }
+
+    if etag != "" {
+        ctx = withRequestHeader(ctx, "If-Match", etag)
+    } else {
+        latest, _, err := c.readRecord(ctx, endpoint, id, agent)
+        if err != nil {
+            return SpireResponse{}, err
+        }
+        if !reflect.DeepEqual(current, latest) {
+            return SpireResponse{}, fmt.Errorf("%s: %w", recordPath(endpoint, id), ErrRecordChanged)
+        }
+    }
+    return c.UpdateRecord(ctx, endpoint, id, agent, merged)
+}
+
+// Fetches the record endpoint/{id} along with its ETag, empty when the server sends none
+func (c *SpireClient) readRecord(ctx context.Context, endpoint, id string, agent SpireAgent) (map[string]interface{}, string, error) {
+    resp, err := SpireRequestGenericContext[map[string]interface{}](ctx, c, recordPath(endpoint, id), agent, "GET", nil)
+    if err != nil {
+        return nil, "", err
+    }
+    if len(resp.Records) != 1 {
+        return nil, "", fmt.Errorf("%s: expected a single record, got %d", recordPath(endpoint, id), len(resp.Records))
+    }
+    return resp.Records[0], resp.Header.Get("ETag"), nil
+}
+
+// DeleteRecord deletes the record endpoint/{id}
+func (c *SpireClient) DeleteRecord(ctx context.Context, endpoint, id string, agent SpireAgent) error {
+    _, err := c.SpireRequestContext(ctx, recordPath(endpoint, id), agent, "DELETE", nil)
+    return err
+}
+
+// Formats a decoded record id, which JSON decodes as float64, without an exponent
+func idString(v interface{}) string {
+    switch id := v.(type) {
+    case float64:
+        return strconv.FormatFloat(id, 'f', -1, 64)
+    case nil:
+        return ""
+    default:
+        return fmt.Sprint(id)
+    }
+}
+
+// Joins the root URL and an endpoint with exactly one slash between them, whether or not either side
+// carries its own; an endpoint that already starts with the root URL is used as is
+func joinURL(root, endpoint string) string {
+    root = strings.TrimRight(root, "/")
+    if root != "" && strings.HasPrefix(endpoint, root) {
+        rest := endpoint[len(root):]
+        if rest == "" || rest[0] == '/' || rest[0] == '?' {
+            return endpoint
+        }
+    }
+    if endpoint == "" || endpoint[0] == '?' {
+        return root + endpoint
+    }
+    return root + "/" + strings.TrimLeft(endpoint, "/")
+}
+
+// Resolves an endpoint against RootURL and Company, rejecting absolute URLs on any other host so that
+// credentials are never sent somewhere other than the configured Spire server
+func (c *SpireClient) endpointURL(endpoint string) (string, error) {
+    u, err := url.Parse(endpoint)
+    if err != nil {
+        return "", fmt.Errorf("invalid endpoint URL: %w", err)
+    }
+    if u.IsAbs() || u.Host != "" {
+        root, err := url.Parse(c.RootURL)
+        if err != nil {
+            return "", fmt.Errorf("invalid RootURL: %w", err)
+        }
+        if !strings.EqualFold(u.Scheme, root.Scheme) || !strings.EqualFold(u.Host, root.Host) {
+            return "", fmt.Errorf("endpoint %s is not on RootURL host %s", u.Redacted(), root.Host)
+        }
+        return endpoint, nil
+    }
+    if c.RequireCompany && c.Company == "" {
+        return "", fmt.Errorf("SpireClient.Company must be set")
+    }
+    return joinURL(c.companyURL(), endpoint), nil
+}
+
+// RootURL extended by the company path segment when Company is set
+func (c *SpireClient) companyURL() string {
+    if c.Company == "" {
+        return c.RootURL
+    }
+    return joinURL(c.RootURL, "/companies/"+url.PathEscape(c.Company))
+}
+
+// Path of a single record under a collection endpoint
+func recordPath(endpoint, id string) string {
+    return endpoint + "/" + url.PathEscape(id)
+}
+
+// Extracts the trailing id from a Location header such as https://host/api/v2/companies/acme/sales/orders/1234
+func locationID(location string) (string, error) {
+    if location == "" {
+        return "", fmt.Errorf("response did not include a Location header")
+    }
+    u, err := url.Parse(location)
+    if err != nil {
+        return "", fmt.Errorf("invalid Location header %q: %w", location, err)
+    }
+    id := path.Base(strings.TrimRight(u.Path, "/"))
+    if id == "" || id == "." || id == "/" {
+        return "", fmt.Errorf("no record id in Location header %q", location)
+    }
+    return id, nil
+}
+
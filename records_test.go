@@ -0,0 +1,98 @@
+package spireclient
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestPatchRecord(t *testing.T) {
+    tests := []struct {
+        name string
+        status int
+        body string
+        wantRecords int
+    }{
+        {"200 with the updated object", http.StatusOK, `{"id":1,"status":"H","customerPO":"PO-9"}`, 1},
+        {"204 without a body", http.StatusNoContent, "", 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if r.Method != http.MethodPatch || r.URL.Path != "/sales/orders/1" {
+                    t.Errorf("sent %s %s, want PATCH /sales/orders/1", r.Method, r.URL.Path)
+                }
+                if body, _ := io.ReadAll(r.Body); string(body) != `{"status":"H"}` {
+                    t.Errorf("payload = %s, want only the changed field", body)
+                }
+                w.WriteHeader(tt.status)
+                w.Write([]byte(tt.body))
+            }))
+            defer server.Close()
+
+            resp, err := NewSpireClient(server.URL).PatchRecord(context.Background(), "/sales/orders", "1", SpireAgent{}, map[string]interface{}{"status": "H"})
+            if err != nil {
+                t.Fatal(err)
+            }
+            if resp.StatusCode != tt.status || len(resp.Records) != tt.wantRecords {
+                t.Fatalf("got status %d with %d records, want %d with %d", resp.StatusCode, len(resp.Records), tt.status, tt.wantRecords)
+            }
+            if tt.wantRecords == 1 && (resp.Records[0]["status"] != "H" || resp.Records[0]["id"] != float64(1)) {
+                t.Errorf("record = %v, want the updated order", resp.Records[0])
+            }
+        })
+    }
+}
+
+func TestJoinURL(t *testing.T) {
+    tests := []struct {
+        root, endpoint, want string
+    }{
+        {"https://spire.test/api/v2", "/sales/orders", "https://spire.test/api/v2/sales/orders"},
+        {"https://spire.test/api/v2/", "/sales/orders", "https://spire.test/api/v2/sales/orders"},
+        {"https://spire.test/api/v2/", "sales/orders", "https://spire.test/api/v2/sales/orders"},
+        {"https://spire.test/api/v2//", "//sales/orders", "https://spire.test/api/v2/sales/orders"},
+        {"https://spire.test/api/v2", "/sales/orders/", "https://spire.test/api/v2/sales/orders/"},
+        {"https://spire.test/api/v2", "?limit=1", "https://spire.test/api/v2?limit=1"},
+        {"https://spire.test/api/v2/", "", "https://spire.test/api/v2"},
+        {"https://spire.test/api/v2/", "https://spire.test/api/v2/sales/orders?start=10", "https://spire.test/api/v2/sales/orders?start=10"},
+        {"https://spire.test/api/v2", "https://spire.test/api/v2", "https://spire.test/api/v2"},
+    }
+    for _, tt := range tests {
+        if got := joinURL(tt.root, tt.endpoint); got != tt.want {
+            t.Errorf("joinURL(%q, %q) = %q, want %q", tt.root, tt.endpoint, got, tt.want)
+        }
+    }
+}
+
+func TestEndpointURL(t *testing.T) {
+    tests := []struct {
+        name string
+        client *SpireClient
+        endpoint string
+        want string
+        wantErr bool
+    }{
+        {"relative", &SpireClient{RootURL: "https://spire.test/api/v2"}, "/sales/orders", "https://spire.test/api/v2/sales/orders", false},
+        {"root with trailing slash", &SpireClient{RootURL: "https://spire.test/api/v2/"}, "sales/orders", "https://spire.test/api/v2/sales/orders", false},
+        {"company", &SpireClient{RootURL: "https://spire.test/api/v2/", Company: "acme"}, "/sales/orders", "https://spire.test/api/v2/companies/acme/sales/orders", false},
+        {"absolute on the root host", &SpireClient{RootURL: "https://spire.test/api/v2", Company: "acme"}, "https://spire.test/api/v2/companies/acme/sales/orders?start=2", "https://spire.test/api/v2/companies/acme/sales/orders?start=2", false},
+        {"absolute on another host", &SpireClient{RootURL: "https://spire.test/api/v2"}, "https://evil.test/api/v2/sales/orders", "", true},
+        {"absolute with another scheme", &SpireClient{RootURL: "https://spire.test/api/v2"}, "http://spire.test/api/v2/sales/orders", "", true},
+        {"scheme-relative on another host", &SpireClient{RootURL: "https://spire.test/api/v2"}, "//evil.test/sales/orders", "", true},
+        {"company required", &SpireClient{RootURL: "https://spire.test/api/v2", RequireCompany: true}, "/sales/orders", "", true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := tt.client.endpointURL(tt.endpoint)
+            if (err != nil) != tt.wantErr {
+                t.Fatalf("endpointURL(%q) error = %v, want error %v", tt.endpoint, err, tt.wantErr)
+            }
+            if got != tt.want {
+                t.Errorf("endpointURL(%q) = %q, want %q", tt.endpoint, got, tt.want)
+            }
+        })
+    }
+}
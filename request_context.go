@@ -0,0 +1,70 @@
+package spireclient
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "net/http"
+)
+
+type requestHeadersKey struct{}
+
+type requestIDKey struct{}
+
+// Header carrying the request id when SpireClient.RequestIDHeader is empty
+const defaultRequestIDHeader = "X-Request-ID"
+
+// WithIdempotencyKey returns a context whose requests carry key in the Idempotency-Key header,
+// letting servers or gateways that support it discard a retried create
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+    return withRequestHeader(ctx, "Idempotency-Key", key)
+}
+
+// WithReturnMinimal returns a context whose requests carry Prefer: return=minimal, asking the server to
+// answer a create or update without echoing the record back, see SpireClient.PreferMinimal
+func WithReturnMinimal(ctx context.Context) context.Context {
+    return withRequestHeader(ctx, "Prefer", "return=minimal")
+}
+
+// WithRequestID returns a context whose requests, including every retry, carry id in the request id
+// header, see SpireClient.RequestIDHeader, so they can be found in the Spire server's logs
+// The id is also reported to the OnRequest hook as RequestInfo.RequestID
+func WithRequestID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Request id set on ctx by WithRequestID, empty when there is none
+func requestID(ctx context.Context) string {
+    id, _ := ctx.Value(requestIDKey{}).(string)
+    return id
+}
+
+// Random id for a request sent without one
+func newRequestID() string {
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
+func (c *SpireClient) requestIDHeader() string {
+    if c.RequestIDHeader == "" {
+        return defaultRequestIDHeader
+    }
+    return c.RequestIDHeader
+}
+
+// Returns a context whose requests carry an extra header, on top of any set by outer contexts
+func withRequestHeader(ctx context.Context, key, value string) context.Context {
+    headers := requestHeaders(ctx).Clone()
+    if headers == nil {
+        headers = make(http.Header)
+    }
+    headers.Set(key, value)
+    return context.WithValue(ctx, requestHeadersKey{}, headers)
+}
+
+// Extra headers carried by ctx, nil when there are none
+func requestHeaders(ctx context.Context) http.Header {
+    headers, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+    return headers
+}
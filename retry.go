@@ -0,0 +1,122 @@
+package spireclient
+
+import (
+    "context"
+    "math/rand/v2"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    defaultRetryBackoff = 500 * time.Millisecond
+    maxRetryBackoff = 30 * time.Second
+)
+
+// Jitter randomises retry delays so that clients failing together do not all retry in lockstep
+type Jitter int
+
+const (
+    // Waits exactly the exponential backoff, for deterministic tests
+    JitterNone Jitter = iota
+    // Waits a random delay between zero and the backoff, spreading retries the most
+    JitterFull
+    // Waits half the backoff plus a random delay up to the other half, keeping a minimum wait
+    JitterEqual
+)
+
+// Applies the jitter strategy to delay
+func (j Jitter) apply(delay time.Duration) time.Duration {
+    if delay <= 0 {
+        return delay
+    }
+    switch j {
+    case JitterFull:
+        return time.Duration(rand.Int64N(int64(delay) + 1))
+    case JitterEqual:
+        half := delay / 2
+        return half + time.Duration(rand.Int64N(int64(delay-half)+1))
+    }
+    return delay
+}
+
+// Reports whether a response status is worth retrying
+func isRetryableStatus(statusCode int) bool {
+    switch statusCode {
+    case http.StatusTooManyRequests,
+        http.StatusInternalServerError,
+        http.StatusBadGateway,
+        http.StatusServiceUnavailable,
+        http.StatusGatewayTimeout:
+        return true
+    }
+    return false
+}
+
+// Only GET and DELETE are retried unless RetryUnsafe is set, since retrying a POST could double-create a record
+func (c *SpireClient) canRetry(method string, attempt int) bool {
+    if attempt >= c.MaxRetries {
+        return false
+    }
+    switch strings.ToUpper(method) {
+    case http.MethodGet, http.MethodDelete:
+        return true
+    }
+    return c.RetryUnsafe
+}
+
+// Exponential backoff from RetryBackoff randomised by RetryJitter, replaced by the server's Retry-After
+// on a 429 when present
+func (c *SpireClient) retryDelay(attempt int, resp *http.Response) time.Duration {
+    if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+        if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+            return delay
+        }
+    }
+
+    base := c.RetryBackoff
+    if base <= 0 {
+        base = defaultRetryBackoff
+    }
+    delay := base << attempt
+    if delay <= 0 || delay > maxRetryBackoff {
+        delay = maxRetryBackoff
+    }
+    return c.RetryJitter.apply(delay)
+}
+
+// Parses a Retry-After header given either in delta-seconds or as an HTTP-date
+func parseRetryAfter(value string) (time.Duration, bool) {
+    value = strings.TrimSpace(value)
+    if value == "" {
+        return 0, false
+    }
+    if seconds, err := strconv.Atoi(value); err == nil {
+        if seconds < 0 {
+            return 0, false
+        }
+        return time.Duration(seconds) * time.Second, true
+    }
+    date, err := http.ParseTime(value)
+    if err != nil {
+        return 0, false
+    }
+    delay := time.Until(date)
+    if delay < 0 {
+        delay = 0
+    }
+    return delay, true
+}
+
+// Waits for d, returning early with ctx.Err() if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
+}
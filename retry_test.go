@@ -0,0 +1,111 @@
+package spireclient
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// RoundTripper that fails its first failures requests with a connection error, then answers 200
+type flakyTransport struct {
+    failures int32
+    calls atomic.Int32
+}
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    if t.calls.Add(1) <= t.failures {
+        return nil, errors.New("connection reset by peer")
+    }
+    return &http.Response{
+        StatusCode: http.StatusOK,
+        Header: http.Header{"Content-Type": {"application/json"}},
+        Body: io.NopCloser(strings.NewReader(`{"records":[],"count":0}`)),
+        Request: req,
+    }, nil
+}
+
+func TestRetriesConnectionErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        method string
+        retryUnsafe bool
+        wantCalls int32
+        wantErr bool
+    }{
+        {"GET retried", http.MethodGet, false, 3, false},
+        {"POST not retried", http.MethodPost, false, 1, true},
+        {"POST retried with RetryUnsafe", http.MethodPost, true, 3, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            transport := &flakyTransport{failures: 2}
+            client := NewSpireClient("http://spire.test",
+                WithHTTPClient(&http.Client{Transport: transport}),
+                WithRetries(3, time.Millisecond),
+                WithRetryJitter(JitterNone))
+            client.RetryUnsafe = tt.retryUnsafe
+
+            var payload interface{}
+            if tt.method == http.MethodPost {
+                payload = map[string]interface{}{"customer": "C1"}
+            }
+            _, err := client.SpireRequestContext(context.Background(), "/sales/orders", SpireAgent{}, tt.method, payload)
+            if (err != nil) != tt.wantErr {
+                t.Errorf("err = %v, want error %v", err, tt.wantErr)
+            }
+            if got := transport.calls.Load(); got != tt.wantCalls {
+                t.Errorf("sent %d requests, want %d", got, tt.wantCalls)
+            }
+        })
+    }
+}
+
+func TestRateLimitedRequestWaitsForRetryAfter(t *testing.T) {
+    tests := []struct {
+        name string
+        retryAfter func() string
+        minWait time.Duration
+    }{
+        {"delta-seconds", func() string { return "1" }, time.Second},
+        {"HTTP-date", func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) }, time.Second},
+        {"missing", func() string { return "" }, 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var calls atomic.Int32
+            server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if calls.Add(1) == 1 {
+                    if value := tt.retryAfter(); value != "" {
+                        w.Header().Set("Retry-After", value)
+                    }
+                    w.WriteHeader(http.StatusTooManyRequests)
+                    return
+                }
+                w.WriteHeader(http.StatusCreated)
+            }))
+            defer server.Close()
+
+            client := NewSpireClient(server.URL, WithRetries(0, time.Millisecond), WithRetryJitter(JitterNone))
+            client.RateLimitRetries = 1
+
+            started := time.Now()
+            resp, err := client.SpireRequestContext(context.Background(), "/sales/orders", SpireAgent{}, http.MethodPost, map[string]interface{}{"customer": "C1"})
+            waited := time.Since(started)
+            if err != nil {
+                t.Fatalf("got %v, want the retry to succeed", err)
+            }
+            if resp.StatusCode != http.StatusCreated || calls.Load() != 2 {
+                t.Errorf("got status %d after %d requests, want 201 after 2", resp.StatusCode, calls.Load())
+            }
+            if waited < tt.minWait-50*time.Millisecond {
+                t.Errorf("retried after %v, want at least %v", waited, tt.minWait)
+            }
+        })
+    }
+}
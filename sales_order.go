@@ -0,0 +1,158 @@
+package spireclient
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+)
+
+// Sales order statuses, as found in an order's status field
+const (
+    SalesOrderStatusOpen = "O"
+    SalesOrderStatusHold = "H"
+    SalesOrderStatusClosed = "C"
+)
+
+// SalesOrder is the body CreateSalesOrder sends to create a sales order
+// Decimal quantities and prices are strings, as Spire sends them, so no precision is lost
+type SalesOrder struct {
+    // Assigned by Spire when empty
+    OrderNo string `json:"orderNo,omitempty"`
+    Customer SalesOrderCustomer `json:"customer"`
+    // Dates are formatted YYYY-MM-DD
+    OrderDate string `json:"orderDate,omitempty"`
+    RequiredDate string `json:"requiredDate,omitempty"`
+    CustomerPO string `json:"customerPO,omitempty"`
+    ReferenceNo string `json:"referenceNo,omitempty"`
+    // Billing address, defaults to the customer's when nil
+    Address *Address `json:"address,omitempty"`
+    // Defaults to the customer's shipping address when nil
+    ShippingAddress *Address `json:"shippingAddress,omitempty"`
+    Items []SalesOrderItem `json:"items,omitempty"`
+    // User-defined fields, see WithUDF
+    UDF UDF `json:"udf,omitempty"`
+}
+
+// SalesOrderCustomer identifies the customer a sales order is for
+type SalesOrderCustomer struct {
+    CustomerNo string `json:"customerNo"`
+}
+
+// Address is a postal address on an order
+type Address struct {
+    Name string `json:"name,omitempty"`
+    Line1 string `json:"line1,omitempty"`
+    Line2 string `json:"line2,omitempty"`
+    Line3 string `json:"line3,omitempty"`
+    Line4 string `json:"line4,omitempty"`
+    City string `json:"city,omitempty"`
+    ProvState string `json:"provState,omitempty"`
+    PostalCode string `json:"postalCode,omitempty"`
+    Country string `json:"country,omitempty"`
+}
+
+// SalesOrderItem is one line of a sales order
+type SalesOrderItem struct {
+    Inventory SalesOrderInventory `json:"inventory"`
+    OrderQty string `json:"orderQty"`
+    // Defaults to the customer's price for the part when empty
+    UnitPrice string `json:"unitPrice,omitempty"`
+    Description string `json:"description,omitempty"`
+    // User-defined fields, see WithUDF
+    UDF UDF `json:"udf,omitempty"`
+}
+
+// SalesOrderInventory identifies the inventory item a sales order line draws from
+type SalesOrderInventory struct {
+    Whse string `json:"whse"`
+    PartNo string `json:"partNo"`
+}
+
+// NewSalesOrder builds a sales order for customerNo with the given lines
+func NewSalesOrder(customerNo string, items ...SalesOrderItem) SalesOrder {
+    return SalesOrder{
+        Customer: SalesOrderCustomer{CustomerNo: customerNo},
+        Items: items,
+    }
+}
+
+// NewSalesOrderItem builds a line ordering qty of partNo from warehouse whse at the customer's price
+func NewSalesOrderItem(whse, partNo string, qty float64) SalesOrderItem {
+    return SalesOrderItem{
+        Inventory: SalesOrderInventory{Whse: whse, PartNo: partNo},
+        OrderQty: strconv.FormatFloat(qty, 'f', -1, 64),
+    }
+}
+
+// WithUnitPrice returns the line with its unit price overridden
+func (i SalesOrderItem) WithUnitPrice(price float64) SalesOrderItem {
+    i.UnitPrice = strconv.FormatFloat(price, 'f', -1, 64)
+    return i
+}
+
+// ValidateSalesOrder checks a sales order payload, a SalesOrder or a map of the same shape, for the fields
+// Spire requires: a customer number, and on every line a part number and a numeric order quantity
+// Unit prices must be numeric and dates formatted YYYY-MM-DD when set
+// The error lists every problem found, e.g. "items[1].inventory.partNo is required"
+func ValidateSalesOrder(payload interface{}) error {
+    jsonBytes, err := json.Marshal(payload)
+    if err != nil {
+        return fmt.Errorf("invalid sales order: %w", err)
+    }
+    var order map[string]interface{}
+    if err := json.Unmarshal(jsonBytes, &order); err != nil {
+        return fmt.Errorf("invalid sales order: must be a JSON object")
+    }
+
+    var problems []error
+    customer, _ := order["customer"].(map[string]interface{})
+    if customerNo, _ := customer["customerNo"].(string); customerNo == "" {
+        problems = append(problems, errors.New("customer.customerNo is required"))
+    }
+    for _, field := range []string{"orderDate", "requiredDate"} {
+        if date, ok := order[field]; ok {
+            if s, _ := date.(string); !isDate(s) {
+                problems = append(problems, fmt.Errorf("%s must be formatted YYYY-MM-DD, got %v", field, date))
+            }
+        }
+    }
+
+    if rawItems, ok := order["items"]; ok && rawItems != nil {
+        items, ok := rawItems.([]interface{})
+        if !ok {
+            problems = append(problems, errors.New("items must be a list"))
+        }
+        for i, rawItem := range items {
+            item, ok := rawItem.(map[string]interface{})
+            if !ok {
+                problems = append(problems, fmt.Errorf("items[%d] must be an object", i))
+                continue
+            }
+            inventory, _ := item["inventory"].(map[string]interface{})
+            if partNo, _ := inventory["partNo"].(string); partNo == "" {
+                problems = append(problems, fmt.Errorf("items[%d].inventory.partNo is required", i))
+            }
+            if _, err := toFloat(item["orderQty"]); err != nil {
+                problems = append(problems, fmt.Errorf("items[%d].orderQty: %w", i, err))
+            }
+            if price, ok := item["unitPrice"]; ok {
+                if _, err := toFloat(price); err != nil {
+                    problems = append(problems, fmt.Errorf("items[%d].unitPrice: %w", i, err))
+                }
+            }
+        }
+    }
+
+    if len(problems) > 0 {
+        return fmt.Errorf("invalid sales order: %w", errors.Join(problems...))
+    }
+    return nil
+}
+
+// Reports whether s is a date formatted YYYY-MM-DD
+func isDate(s string) bool {
+    _, err := time.Parse(time.DateOnly, s)
+    return err == nil
+}
@@ -0,0 +1,72 @@
+package spireclient
+
+import (
+    "encoding/json"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestSalesOrderJSONRoundTrip(t *testing.T) {
+    order := NewSalesOrder("C1",
+        NewSalesOrderItem("00", "A-100", 2).WithUnitPrice(3.5),
+        NewSalesOrderItem("01", "B-200", 0.25).WithUDF("lot", "L7"),
+    ).WithUDF("channel", "web")
+    order.OrderDate = "2026-01-02"
+    order.CustomerPO = "PO-9"
+    order.ShippingAddress = &Address{Name: "Dock 4", Line1: "1 Main St", City: "Toronto", ProvState: "ON", PostalCode: "M5V 1A1", Country: "CAN"}
+
+    const want = `{"customer":{"customerNo":"C1"},"orderDate":"2026-01-02","customerPO":"PO-9",` +
+        `"shippingAddress":{"name":"Dock 4","line1":"1 Main St","city":"Toronto","provState":"ON","postalCode":"M5V 1A1","country":"CAN"},` +
+        `"items":[{"inventory":{"whse":"00","partNo":"A-100"},"orderQty":"2","unitPrice":"3.5"},` +
+        `{"inventory":{"whse":"01","partNo":"B-200"},"orderQty":"0.25","udf":{"lot":"L7"}}],` +
+        `"udf":{"channel":"web"}}`
+    got, err := json.Marshal(order)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(got) != want {
+        t.Fatalf("marshaled\n%s\nwant\n%s", got, want)
+    }
+
+    var decoded SalesOrder
+    if err := json.Unmarshal(got, &decoded); err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(decoded, order) {
+        t.Errorf("round trip gave %+v, want %+v", decoded, order)
+    }
+}
+
+func TestValidateSalesOrder(t *testing.T) {
+    order := NewSalesOrder("C1", NewSalesOrderItem("00", "A-100", 2).WithUnitPrice(3.5))
+    order.OrderDate = "2026-01-02"
+    if err := ValidateSalesOrder(order); err != nil {
+        t.Fatalf("valid order: %v", err)
+    }
+
+    bad := map[string]interface{}{
+        "orderDate": "01/02/2026",
+        "items": []interface{}{
+            map[string]interface{}{"inventory": map[string]interface{}{"partNo": "A-100"}, "orderQty": "5 boxes"},
+            map[string]interface{}{"orderQty": "1", "unitPrice": "12abc"},
+            map[string]interface{}{"inventory": map[string]interface{}{"partNo": "B-200"}, "orderQty": "NaN"},
+        },
+    }
+    err := ValidateSalesOrder(bad)
+    if err == nil {
+        t.Fatal("expected an error")
+    }
+    for _, want := range []string{
+        "customer.customerNo is required",
+        "orderDate must be formatted YYYY-MM-DD",
+        `items[0].orderQty: invalid number "5 boxes"`,
+        "items[1].inventory.partNo is required",
+        `items[1].unitPrice: invalid number "12abc"`,
+        `items[2].orderQty: invalid number "NaN"`,
+    } {
+        if !strings.Contains(err.Error(), want) {
+            t.Errorf("error %q does not mention %q", err, want)
+        }
+    }
+}
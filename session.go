@@ -0,0 +1,66 @@
+package spireclient
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+)
+
+// Login exchanges the agent's username and password for a session token at LoginEndpoint and stores
+// it in agent.Token, so later requests send the token instead of the password
+// The endpoint receives the credentials once as basic auth and must answer with {"token": "..."}
+//
+// With AutoReauth, when a request made with a token is rejected with a 401, the client logs in again
+// with the agent's credentials and retries the request once; the renewed token is used for that
+// username from then on, even when a caller still holds the old one
+func (c *SpireClient) Login(ctx context.Context, agent *SpireAgent) error {
+    token, err := c.login(ctx, *agent)
+    if err != nil {
+        return err
+    }
+    agent.Token = token
+    return nil
+}
+
+func (c *SpireClient) login(ctx context.Context, agent SpireAgent) (string, error) {
+    if c.LoginEndpoint == "" {
+        return "", fmt.Errorf("session login requires SpireClient.LoginEndpoint to be set")
+    }
+
+    agent.Token = ""
+    _, body, err := c.doBody(ctx, c.LoginEndpoint, agent, "POST", nil)
+    if err != nil {
+        return "", fmt.Errorf("error logging in to Spire: %w", err)
+    }
+
+    var session struct {
+        Token string `json:"token"`
+    }
+    if err := json.Unmarshal(body, &session); err != nil {
+        return "", fmt.Errorf("error unmarshaling Spire session: %w", err)
+    }
+    if session.Token == "" {
+        return "", fmt.Errorf("Spire login response did not include a token")
+    }
+
+    c.sessions.Store(agent.Username, session.Token)
+    return session.Token, nil
+}
+
+// Authorization header for the agent: its session token when it has one, preferring a token renewed
+// by this client, otherwise basic credentials
+func (c *SpireClient) authorization(agent SpireAgent) string {
+    if agent.Token == "" {
+        return agent.BasicAuthHeader()
+    }
+    token := agent.Token
+    if renewed, ok := c.sessions.Load(agent.Username); ok {
+        token = renewed.(string)
+    }
+    return "Bearer " + token
+}
+
+// A session can only be renewed for a token agent that still holds its credentials
+func (a SpireAgent) canLogin() bool {
+    return a.Token != "" && a.Username != "" && a.Password != ""
+}
@@ -0,0 +1,28 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const shipmentsEndpoint = "/sales/shipments"
+
+// GetShipment fetches a single shipment by its numeric id
+// A missing shipment returns an error matching ErrNotFound
+func (c *SpireClient) GetShipment(ctx context.Context, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.GetRecord(ctx, shipmentsEndpoint, id, agent)
+}
+
+// ListShipments gets ALL shipments matching filters, paginating like FetchSpireData
+func (c *SpireClient) ListShipments(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, shipmentsEndpoint, filters, agent)
+}
+
+// GetOrderShipments gets every shipment of the sales order orderNo
+func (c *SpireClient) GetOrderShipments(ctx context.Context, orderNo string, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.ListShipments(ctx, Equals("orderNo", orderNo), agent)
+}
+
+// CreateShipment creates a shipment and returns its id, taken from the Location header
+func (c *SpireClient) CreateShipment(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    return c.CreateRecordWithID(ctx, shipmentsEndpoint, agent, payload)
+}
@@ -1,148 +1,758 @@
 package spireclient
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"encoding/base64"
+	"errors"
 	"io"
 	"bytes"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Version of this client, reported in the default User-Agent
+const Version = "0.1.0"
+
+// Identifies this client to the Spire server when SpireClient.UserAgent is unset
+const defaultUserAgent = "go-spire-api-client/" + Version
+
+// Number of records requested per page when SpireClient.PageLimit is unset
+const defaultPageLimit = 1000
+
+// Pages a single fetch may request when SpireClient.MaxPages is zero
+const defaultMaxPages = 1000
+
+// Timeout of each individual request made by a client from NewSpireClient
+const defaultTimeout = 10 * time.Second
+
 // API client configuration
 type SpireClient struct {
+    // Base URL that endpoints are joined to, without a trailing slash
     RootURL string
+    // Company whose data every relative endpoint addresses, added to RootURL as /companies/{Company}
+    // Leave empty when RootURL already selects the company
+    Company string
+    // Fails every request while Company is empty, for deployments hosting several companies
+    RequireCompany bool
     HTTPClient *http.Client
+    // Filters merged into every fetch of an endpoint, keyed by the endpoint as passed, e.g. "/customers"
+    // The merge is by top-level key and the caller's filter wins: a key it sets, including $and or $or,
+    // replaces the default's clause for that key
+    // Lookups such as GetRecordByField, GetInventoryItem and the check of CreateSalesOrderIdempotent
+    // ignore them, so a default cannot hide the record they look for
+    DefaultFilters map[string]Filter
+    // Checks filters with ValidateFilter before any fetch, failing fast on an unknown operator
+    // Leave off when the server supports operators ValidateFilter doesn't know
+    ValidateFilters bool
+    // Records requested per page by FetchSpireData, defaults to 1000 when zero
+    PageLimit int
+    // Pages a single FetchSpireData call may request before failing with ErrMaxPagesExceeded,
+    // guarding against runaway pulls; defaults to 1000 when zero, negative removes the cap
+    MaxPages int
+    // Retries after a connection error or a 429/500/502/503/504 response, zero disables retries
+    MaxRetries int
+    // Delay before the first retry, doubled on each subsequent one (defaults to 500ms when zero)
+    RetryBackoff time.Duration
+    // Randomisation of the retry backoff, JitterFull from NewSpireClient
+    // Delays from a Retry-After header are never randomised
+    RetryJitter Jitter
+    // Allows retrying non-idempotent methods such as POST, which risks creating duplicates
+    RetryUnsafe bool
+    // Retries of a 429 response for any method, waiting for Retry-After when the server sends it
+    // Once exhausted, further 429s fall back to the MaxRetries rules
+    RateLimitRetries int
+    // Requests per second the client sends at most, waiting before each request and retry to stay below
+    // a server-side ceiling; zero disables the limit
+    RateLimit float64
+    // Requests that may be sent at once after a quiet period, defaults to 1 when zero
+    RateBurst int
+    // Pages FetchSpireData requests in parallel once the first page reports the total count
+    // Zero or one fetches pages sequentially
+    Concurrency int
+    // Sent as the User-Agent header, defaults to go-spire-api-client/<Version> when empty
+    UserAgent string
+    // Header carrying request ids set with WithRequestID, defaults to X-Request-ID when empty
+    RequestIDHeader string
+    // Gives every request without a WithRequestID id a random one, kept across its retries
+    GenerateRequestIDs bool
+    // Extra headers sent with every request, e.g. an API gateway key
+    // They never replace the Authorization header
+    Headers http.Header
+    // How long a successful ValidateSpireCredentials is remembered per username, zero always revalidates
+    ValidationTTL time.Duration
+    // Checks payloads with ValidateSalesOrder before creating sales orders, failing without a request
+    ValidateSalesOrders bool
+    // Order or invoice numbers per request made by GetOrderItems and GetSalesInvoiceItems, defaults to 100 when zero
+    OrderItemsBatchSize int
+    // Path, relative to RootURL, that Login exchanges credentials at for a session token
+    LoginEndpoint string
+    // On a 401 to an agent using a session token, log in again with its credentials and retry once
+    // Enabled by NewSpireClient
+    AutoReauth bool
+    // Called after every HTTP request, including each retry attempt, e.g. to feed a structured logger
+    // The RequestInfo never contains credentials
+    OnRequest func(RequestInfo)
+    // Decodes numbers in records as json.Number rather than float64, keeping quantities and amounts exact
+    // Convert them with ToRat, or json.Number's own methods
+    UseNumber bool
+    // Receives the endpoint, method, status and duration of every HTTP request, nil records nothing
+    Metrics Metrics
+    // Sends Prefer: return=minimal with every POST, PUT and PATCH so a server honouring it answers without
+    // echoing the record back; ids are still read from the Location header, but UpdateRecord and the
+    // like then return no records
+    PreferMinimal bool
+    // Logs POST, PUT, PATCH and DELETE requests instead of sending them, answering each with a synthetic
+    // success; GET requests and Login are still sent so a dry run reflects real data
+    DryRun bool
+    // How long FetchSpireData results are reused for an identical call, zero disables caching
+    // Cached records are shared between callers and must not be modified
+    // Lookups such as GetInventoryItem, GetOrderItems and the check of CreateSalesOrderIdempotent
+    // always ask the server
+    CacheTTL time.Duration
+    // Results kept by the cache, defaults to 256 when zero
+    CacheSize int
+
+    // Consecutive connection errors or 5xx responses that open the circuit breaker, zero disables it
+    // While open, requests fail fast with ErrCircuitOpen
+    BreakerThreshold int
+    // Failures only count as consecutive within this window of the first, zero counts them however far apart
+    BreakerWindow time.Duration
+    // How long the circuit stays open before a single probe request is allowed, defaults to 30s when zero
+    BreakerCooldown time.Duration
+    // Fields added here must also be copied by WithRootURL
+
+    validated validationCache
+    cache responseCache
+    breaker circuitBreaker
+    limiter rateLimiter
+    sessions sync.Map
 }
 
 // SpireAgent holds the authentication details (must be passed in every request)
 type SpireAgent struct {
     Username string
     Password string
+    // Session token from Login, sent instead of basic credentials when set
+    Token string
 }
 
 // SpireClient constructor
-func NewSpireClient(rootURL string) *SpireClient {
-    return &SpireClient{
-        RootURL: rootURL,
+// Without options the client uses a 10 second timeout per request
+func NewSpireClient(rootURL string, opts ...Option) *SpireClient {
+    c := &SpireClient{
+        RootURL: strings.TrimRight(rootURL, "/"),
         HTTPClient: &http.Client{
-            Timeout: 10 * time.Second, 
+            Timeout: defaultTimeout, 
         },
+        AutoReauth: true,
+        RetryJitter: JitterFull,
+    }
+    for _, opt := range opts {
+        opt(c)
+    }
+    return c
+}
+
+// WithRootURL returns a copy of the client addressing rootURL, e.g. to read from production and write
+// to staging with the same settings
+// The copy shares the HTTP client and every option, while the validation and response caches, the
+// circuit breaker, the rate limiter and renewed sessions, which belong to a single server, start afresh
+func (c *SpireClient) WithRootURL(rootURL string) *SpireClient {
+    return &SpireClient{
+        RootURL: strings.TrimRight(rootURL, "/"),
+        Company: c.Company,
+        RequireCompany: c.RequireCompany,
+        HTTPClient: c.HTTPClient,
+        DefaultFilters: copyDefaultFilters(c.DefaultFilters),
+        ValidateFilters: c.ValidateFilters,
+        PageLimit: c.PageLimit,
+        MaxPages: c.MaxPages,
+        MaxRetries: c.MaxRetries,
+        RetryBackoff: c.RetryBackoff,
+        RetryJitter: c.RetryJitter,
+        RetryUnsafe: c.RetryUnsafe,
+        RateLimitRetries: c.RateLimitRetries,
+        RateLimit: c.RateLimit,
+        RateBurst: c.RateBurst,
+        Concurrency: c.Concurrency,
+        UserAgent: c.UserAgent,
+        RequestIDHeader: c.RequestIDHeader,
+        GenerateRequestIDs: c.GenerateRequestIDs,
+        Headers: c.Headers.Clone(),
+        ValidationTTL: c.ValidationTTL,
+        ValidateSalesOrders: c.ValidateSalesOrders,
+        OrderItemsBatchSize: c.OrderItemsBatchSize,
+        LoginEndpoint: c.LoginEndpoint,
+        AutoReauth: c.AutoReauth,
+        OnRequest: c.OnRequest,
+        UseNumber: c.UseNumber,
+        Metrics: c.Metrics,
+        PreferMinimal: c.PreferMinimal,
+        DryRun: c.DryRun,
+        CacheTTL: c.CacheTTL,
+        CacheSize: c.CacheSize,
+        BreakerThreshold: c.BreakerThreshold,
+        BreakerWindow: c.BreakerWindow,
+        BreakerCooldown: c.BreakerCooldown,
     }
 }
 
 // Generates the basic authentication headers required by Spire
+// Every request sent with basic authentication, including ValidateSpireCredentials, uses this header
 func (a SpireAgent) BasicAuthHeader() string {
     encodedCredentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
     return "Basic " + encodedCredentials
 }
 
+// Replaces the password in every printed form of a SpireAgent
+const redactedPassword = "[REDACTED]"
+
+// String describes the agent for logging without revealing the password
+func (a SpireAgent) String() string {
+    return fmt.Sprintf("SpireAgent{Username: %s, Password: %s}", a.Username, redactedPassword)
+}
+
+// GoString keeps %#v from printing the password
+func (a SpireAgent) GoString() string {
+    return fmt.Sprintf("spireclient.SpireAgent{Username:%q, Password:%q}", a.Username, redactedPassword)
+}
+
+// LogValue keeps log/slog from recording the password
+func (a SpireAgent) LogValue() slog.Value {
+    return slog.GroupValue(
+        slog.String("username", a.Username),
+        slog.String("password", redactedPassword),
+    )
+}
+
+// Shortest raw password or token redact replaces; shorter ones would match inside ordinary words and
+// garble the message, e.g. a password "e" in "order reference required"
+const minRedactedLength = 4
+
+// Strips the agent's credentials, raw or base64 encoded, and session token from text such as a
+// response body a server may echo them in
+// The base64 credentials are always replaced, the raw password and token only from minRedactedLength
+func (a SpireAgent) redact(text string) string {
+    if len(a.Token) >= minRedactedLength {
+        text = strings.ReplaceAll(text, a.Token, redactedPassword)
+    }
+    if a.Password == "" {
+        return text
+    }
+    encodedCredentials := strings.TrimPrefix(a.BasicAuthHeader(), "Basic ")
+    text = strings.ReplaceAll(text, encodedCredentials, redactedPassword)
+    if len(a.Password) < minRedactedLength {
+        return text
+    }
+    return strings.ReplaceAll(text, a.Password, redactedPassword)
+}
+
+// SpireError is returned for any response with a non-200/201/204 status, and for a request that
+// never got a response, in which case Status is StatusConnectionError and StatusCode is zero
+// Use errors.As to inspect the StatusCode and the raw response body in Detail
 type SpireError struct {
+    StatusCode int
     Status string
     Detail string
+    // Transport error behind a connection failure, e.g. a *net.DNSError or a TLS certificate error,
+    // reachable through errors.As
+    Err error
 }
 
+// Status of a SpireError for a request that failed before any response arrived
+const StatusConnectionError = "connection_error"
+
 func (e *SpireError) Error() string {
+    if e.Status == StatusConnectionError {
+        return e.Detail
+    }
     return fmt.Sprintf("API request failed with status %s. Details: %s", e.Status, e.Detail)
 }
 
+func (e *SpireError) Unwrap() error {
+    return e.Err
+}
+
+// ErrNotFound matches any *SpireError with a 404 status via errors.Is
+var ErrNotFound = errors.New("spire record not found")
+
+// ErrMultipleMatches is returned by lookups expecting exactly one record when several match
+var ErrMultipleMatches = errors.New("multiple spire records match")
+
+// ErrMaxPagesExceeded is returned, with the records fetched so far, when a fetch would need more
+// than SpireClient.MaxPages pages
+var ErrMaxPagesExceeded = errors.New("spire fetch exceeded the maximum number of pages")
+
+// ErrRecordChanged is returned by MergeRecord when the record changed between its read and its write,
+// and matches any *SpireError with a 412 status via errors.Is
+var ErrRecordChanged = errors.New("spire record changed since it was read")
+
+// Is reports a 404 SpireError as ErrNotFound and a 412 as ErrRecordChanged
+func (e *SpireError) Is(target error) bool {
+    switch target {
+    case ErrNotFound:
+        return e.StatusCode == http.StatusNotFound
+    case ErrRecordChanged:
+        return e.StatusCode == http.StatusPreconditionFailed
+    }
+    return false
+}
+
+// PartialResultError is returned alongside the records gathered so far when a fetch fails partway,
+// e.g. because the deadline bounding the whole pagination loop passed or a later page failed
+// It unwraps to the underlying error, so errors.Is(err, context.DeadlineExceeded) holds
+type PartialResultError struct {
+    // Records returned with the error
+    Received int
+    // Total count reported by the first page
+    Total int
+    // Offset to resume from with FetchOptions.Start
+    Next int
+    Err error
+}
+
+func (e *PartialResultError) Error() string {
+    return fmt.Sprintf("fetch stopped after %d of %d records: %v", e.Received, e.Total, e.Err)
+}
+
+func (e *PartialResultError) Unwrap() error {
+    return e.Err
+}
+
 // Generic version of SpireResponse
 type spireResponseBase[T any] struct {
     Records []T     `json:"records"`
-    Count   float64 `json:"count"`
+    Count   int     `json:"count"`
+    Start int `json:"start"`
+    Limit int `json:"limit"`
+    NextPage string `json:"nextPage"`
+    StatusCode int `json:"-"`
+    Header http.Header `json:"-"`
 }
 
 type SpireResponse struct {
     Records []map[string]interface{} `json:"records"`
-    Count   float64                  `json:"count"`
+    // Total number of matching records; a non-integer or negative count fails decoding
+    Count   int                      `json:"count"`
+    // Offset and page size of this page, zero when the Spire version does not report them
+    Start int `json:"start"`
+    Limit int `json:"limit"`
+    // Link to the following page, empty on the last page or when the Spire version does not send one
+    NextPage string `json:"nextPage"`
+    // Status of the response, e.g. to tell a 201 Created from a 200
+    StatusCode int `json:"-"`
+    // Headers of the response, such as Location after a create
+    Header http.Header `json:"-"`
 }
 
 // SpireRequestGeneric allows unmarshaling into specific structs
 // Performs an HTTP request to the Spire server handles payload marshaling, and authentication
 func SpireRequestGeneric[T any](c *SpireClient, endpoint string, agent SpireAgent, method string, payload interface{}) (spireResponseBase[T], error) {
-    var bodyReader io.Reader
-    if payload != nil {
-        payloadBytes, err := json.Marshal(payload)
+    return SpireRequestGenericContext[T](context.Background(), c, endpoint, agent, method, payload)
+}
+
+// SpireRequestGenericContext is SpireRequestGeneric bound to ctx, cancelling the request when ctx is done
+func SpireRequestGenericContext[T any](ctx context.Context, c *SpireClient, endpoint string, agent SpireAgent, method string, payload interface{}) (spireResponseBase[T], error) {
+    resp, body, err := c.doBody(ctx, endpoint, agent, method, payload)
+    if err != nil {
+        return spireResponseBase[T]{}, err
+    }
+
+    var result spireResponseBase[T]
+    if hasRecordsBody(resp.StatusCode) && !isEmptyBody(body) {
+        result, err = decodeSpireResponse[T](body, c.UseNumber)
         if err != nil {
-            return spireResponseBase[T]{}, fmt.Errorf("failed to marshal payload: %w", err)
+            return spireResponseBase[T]{}, decodeError(endpoint, agent, body, err)
+        }
+    }
+    result.StatusCode = resp.StatusCode
+    result.Header = resp.Header
+    return result, nil
+}
+
+// Decodes either the paginated records/count wrapper or, as returned by a PUT or a GET by id,
+// a bare object which becomes the single record
+func decodeSpireResponse[T any](body []byte, useNumber bool) (spireResponseBase[T], error) {
+    var envelope map[string]json.RawMessage
+    if err := json.Unmarshal(body, &envelope); err != nil {
+        return spireResponseBase[T]{}, err
+    }
+
+    var result spireResponseBase[T]
+    if _, ok := envelope["records"]; ok {
+        if err := unmarshalJSON(body, &result, useNumber); err != nil {
+            return spireResponseBase[T]{}, err
         }
-        bodyReader = bytes.NewReader(payloadBytes)
+        if result.Count < 0 {
+            return spireResponseBase[T]{}, fmt.Errorf("invalid record count %d", result.Count)
+        }
+        return result, nil
+    }
+
+    var record T
+    if err := unmarshalJSON(body, &record, useNumber); err != nil {
+        return spireResponseBase[T]{}, err
     }
+    result.Records = []T{record}
+    result.Count = 1
+    return result, nil
+}
 
-    req, err := http.NewRequest(method, c.RootURL+endpoint, bodyReader)
+// SpireRawResponse is the undecoded HTTP response alongside the decoded SpireResponse
+type SpireRawResponse struct {
+    StatusCode int
+    Header http.Header
+    Body []byte
+    // Decoded from Body on a success other than 201 or 204, e.g. a 200 or a 206, empty otherwise
+    Response SpireResponse
+}
+
+// SpireRequestRaw performs the same request as SpireRequestContext but also returns the status code,
+// headers and body, e.g. to read the Location header of a 201 Created
+// On a failed status the raw response is returned together with the *SpireError
+func (c *SpireClient) SpireRequestRaw(ctx context.Context, endpoint string, agent SpireAgent, method string, payload interface{}) (SpireRawResponse, error) {
+    resp, body, err := c.doBody(ctx, endpoint, agent, method, payload)
+    if resp == nil {
+        return SpireRawResponse{}, err
+    }
+    raw := SpireRawResponse{
+        StatusCode: resp.StatusCode,
+        Header: resp.Header,
+        Body: body,
+    }
     if err != nil {
-        return spireResponseBase[T]{}, fmt.Errorf("error creating request: %w", err)
+        return raw, err
+    }
+
+    if hasRecordsBody(resp.StatusCode) && !isEmptyBody(body) {
+        if err := unmarshalJSON(body, &raw.Response, c.UseNumber); err != nil {
+            return raw, decodeError(endpoint, agent, body, err)
+        }
+    }
+    return raw, nil
+}
+
+// SpireRequestObject performs a request against an endpoint that answers with a single bare JSON object,
+// such as a GET by id, rather than the records/count wrapper, and decodes that object
+// An empty body, e.g. from a 204, returns a nil map
+func (c *SpireClient) SpireRequestObject(ctx context.Context, endpoint string, agent SpireAgent, method string, payload interface{}) (map[string]interface{}, error) {
+    _, body, err := c.doBody(ctx, endpoint, agent, method, payload)
+    if err != nil {
+        return nil, err
+    }
+    if isEmptyBody(body) {
+        return nil, nil
+    }
+
+    var object map[string]interface{}
+    if err := unmarshalJSON(body, &object, c.UseNumber); err != nil {
+        return nil, decodeError(endpoint, agent, body, err)
     }
+    return object, nil
+}
+
+// Bytes of the body quoted in a decode error
+const decodeErrorSnippet = 200
 
-    if payload != nil {
-        req.Header.Set("Content-Type", "application/json")
+// Some misconfigured endpoints answer 200 with no body at all, which is treated as no records
+func isEmptyBody(body []byte) bool {
+    return len(bytes.TrimSpace(body)) == 0
+}
+
+// Describes a body that is not the JSON expected, naming the endpoint and quoting the start of the body
+func decodeError(endpoint string, agent SpireAgent, body []byte, err error) error {
+    snippet := body
+    if len(snippet) > decodeErrorSnippet {
+        snippet = snippet[:decodeErrorSnippet]
     }
-    req.Header.Set("Authorization", agent.BasicAuthHeader())
+    return fmt.Errorf("error unmarshaling JSON from %s: %w (body: %q)", endpoint, err, agent.redact(string(snippet)))
+}
 
-    resp, err := c.HTTPClient.Do(req)
+// Sends the request and reads the whole body
+// A failed status returns the response and body together with a *SpireError
+func (c *SpireClient) doBody(ctx context.Context, endpoint string, agent SpireAgent, method string, payload interface{}) (*http.Response, []byte, error) {
+    resp, err := c.do(ctx, endpoint, agent, method, payload)
     if err != nil {
-        return spireResponseBase[T]{}, fmt.Errorf("error making request to %s: %w", c.RootURL+endpoint, err)
+        return nil, nil, err
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
-        responseBody, _ := io.ReadAll(resp.Body)
-        return spireResponseBase[T]{}, fmt.Errorf("API request failed with status %s. Details: %s", resp.Status, string(responseBody))
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, nil, fmt.Errorf("error reading response body: %w", err)
     }
 
-    if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusNoContent {
-        return spireResponseBase[T]{}, nil
+    if !isSuccessStatus(resp.StatusCode) {
+        return resp, body, &SpireError{
+            StatusCode: resp.StatusCode,
+            Status: resp.Status,
+            Detail: agent.redact(string(body)),
+        }
     }
+    return resp, body, nil
+}
 
-    var result spireResponseBase[T]
-    if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-        return spireResponseBase[T]{}, fmt.Errorf("error unmarshaling JSON: %w", err)
+// Spire answers successful calls with 200, 201 or 204, and a partial list with 206; any 2xx is a success
+func isSuccessStatus(statusCode int) bool {
+    return statusCode >= 200 && statusCode < 300
+}
+
+// Reports whether a successful response carries records to decode; a 201 is read for its Location
+// header and a 204 has no body
+func hasRecordsBody(statusCode int) bool {
+    return statusCode != http.StatusCreated && statusCode != http.StatusNoContent
+}
+
+// Sends the request, retrying transient failures according to the client's retry settings
+// The caller is responsible for closing the returned response body
+func (c *SpireClient) do(ctx context.Context, endpoint string, agent SpireAgent, method string, payload interface{}) (*http.Response, error) {
+    var payloadBytes []byte
+    contentType := "application/json"
+    switch body := payload.(type) {
+    case nil:
+    case rawBody:
+        payloadBytes, contentType = body.data, body.contentType
+    default:
+        var err error
+        payloadBytes, err = json.Marshal(payload)
+        if err != nil {
+            return nil, fmt.Errorf("failed to marshal payload: %w", err)
+        }
     }
 
-    return result, nil
+    target, err := c.endpointURL(endpoint)
+    if err != nil {
+        return nil, err
+    }
+    if c.DryRun && method != http.MethodGet && method != http.MethodHead && endpoint != c.LoginEndpoint {
+        return dryRunResponse(ctx, method, target, payloadBytes)
+    }
+
+    id := requestID(ctx)
+    if id == "" && c.GenerateRequestIDs {
+        id = newRequestID()
+    }
+
+    retries, rateLimitRetries := 0, 0
+    reauthenticated := false
+    for {
+        var bodyReader io.Reader
+        if payloadBytes != nil {
+            bodyReader = bytes.NewReader(payloadBytes)
+        }
+
+        req, err := http.NewRequestWithContext(ctx, method, target, bodyReader)
+        if err != nil {
+            return nil, fmt.Errorf("error creating request: %w", err)
+        }
+
+        // Building the request and waiting for the rate limiter first means a half-open probe, once
+        // allowed, always reaches recordOutcome
+        if err := c.waitRate(ctx); err != nil {
+            return nil, err
+        }
+        if err := c.allowRequest(); err != nil {
+            return nil, err
+        }
+
+        for _, headers := range []http.Header{c.Headers, requestHeaders(ctx)} {
+            for key, values := range headers {
+                if http.CanonicalHeaderKey(key) == "Authorization" {
+                    continue
+                }
+                req.Header.Del(key)
+                for _, v := range values {
+                    req.Header.Add(key, v)
+                }
+            }
+        }
+        if payload != nil {
+            req.Header.Set("Content-Type", contentType)
+        }
+        if id != "" {
+            req.Header.Set(c.requestIDHeader(), id)
+        }
+        if c.PreferMinimal && req.Header.Get("Prefer") == "" && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch) {
+            req.Header.Set("Prefer", "return=minimal")
+        }
+        if req.Header.Get("Accept-Encoding") == "" {
+            req.Header.Set("Accept-Encoding", "gzip")
+        }
+        req.Header.Set("Authorization", c.authorization(agent))
+        userAgent := c.UserAgent
+        if userAgent == "" {
+            userAgent = defaultUserAgent
+        }
+        req.Header.Set("User-Agent", userAgent)
+
+        started := time.Now()
+        resp, err := c.HTTPClient.Do(req)
+        c.observe(req, resp, err, started, retries+rateLimitRetries)
+        c.recordOutcome(ctx, resp, err)
+        if err != nil {
+            if ctx.Err() == nil && c.canRetry(method, retries) {
+                if err := sleepContext(ctx, c.retryDelay(retries, nil)); err != nil {
+                    return nil, err
+                }
+                retries++
+                continue
+            }
+            if ctx.Err() != nil {
+                return nil, fmt.Errorf("error making request to %s: %w", req.URL.Redacted(), err)
+            }
+            return nil, &SpireError{
+                Status: StatusConnectionError,
+                Detail: fmt.Sprintf("error making request to %s: %v", req.URL.Redacted(), err),
+                Err: err,
+            }
+        }
+
+        if resp.StatusCode == http.StatusUnauthorized {
+            c.validated.forget(agent)
+        }
+        if resp.StatusCode == http.StatusUnauthorized && c.AutoReauth && !reauthenticated && agent.canLogin() {
+            io.Copy(io.Discard, resp.Body)
+            resp.Body.Close()
+            reauthenticated = true
+            if agent.Token, err = c.login(ctx, agent); err != nil {
+                return nil, fmt.Errorf("error renewing Spire session after 401: %w", err)
+            }
+            continue
+        }
+
+        var delay time.Duration
+        switch {
+        case resp.StatusCode == http.StatusTooManyRequests && rateLimitRetries < c.RateLimitRetries:
+            delay = c.retryDelay(rateLimitRetries, resp)
+            rateLimitRetries++
+        case isRetryableStatus(resp.StatusCode) && c.canRetry(method, retries):
+            delay = c.retryDelay(retries, resp)
+            retries++
+        default:
+            if err := decompressResponse(resp); err != nil {
+                return nil, err
+            }
+            return resp, nil
+        }
+
+        io.Copy(io.Discard, resp.Body)
+        resp.Body.Close()
+        if err := sleepContext(ctx, delay); err != nil {
+            return nil, err
+        }
+    }
 }
 
 func (c *SpireClient) SpireRequest(endpoint string, agent SpireAgent, method string, payload interface{}) (SpireResponse, error) {
+    return c.SpireRequestContext(context.Background(), endpoint, agent, method, payload)
+}
+
+// SpireRequestContext is SpireRequest bound to ctx, cancelling the request when ctx is done
+func (c *SpireClient) SpireRequestContext(ctx context.Context, endpoint string, agent SpireAgent, method string, payload interface{}) (SpireResponse, error) {
     // Call the generic version with a map
-    resp, err := SpireRequestGeneric[map[string]interface{}](c, endpoint, agent, method, payload)
+    resp, err := SpireRequestGenericContext[map[string]interface{}](ctx, c, endpoint, agent, method, payload)
     if err != nil {
         return SpireResponse{}, err
     }
     // Convert base response back to the named SpireResponse type
-    return SpireResponse{Records: resp.Records, Count: resp.Count}, nil
+    return SpireResponse{
+        Records: resp.Records,
+        Count: resp.Count,
+        Start: resp.Start,
+        Limit: resp.Limit,
+        NextPage: resp.NextPage,
+        StatusCode: resp.StatusCode,
+        Header: resp.Header,
+    }, nil
 }
 
 // Attempts to get rool url to check if provided credentials are valid
 func (c *SpireClient) ValidateSpireCredentials(agent SpireAgent) error {
-    reqURL := c.RootURL
-    
-    req, err := http.NewRequest("GET", reqURL, nil)
-    if err != nil {
-        return fmt.Errorf("error creating validation request: %w", err)
+    return c.ValidateSpireCredentialsContext(context.Background(), agent)
+}
+
+// ValidateSpireCredentialsContext is ValidateSpireCredentials bound to ctx
+// Within ValidationTTL of a successful validation for the same agent, no request is made
+func (c *SpireClient) ValidateSpireCredentialsContext(ctx context.Context, agent SpireAgent) error {
+    if c.ValidationTTL > 0 && c.validated.valid(agent) {
+        return nil
     }
-    
-    req.Header.Set("Authorization", agent.BasicAuthHeader()) 
-    
-    resp, err := c.HTTPClient.Do(req)
+
+    resp, err := c.do(ctx, "", agent, "GET", nil)
     if err != nil {
         return fmt.Errorf("error calling Spire validation API: %w", err)
     }
     defer resp.Body.Close()
 
     if resp.StatusCode != http.StatusOK {
+        c.validated.forget(agent)
         body, _ := io.ReadAll(resp.Body) 
         return &SpireError{
+            StatusCode: resp.StatusCode,
             Status: resp.Status,
-            Detail: string(body),
+            Detail: agent.redact(string(body)),
         }
     }
+
+    if c.ValidationTTL > 0 {
+        c.validated.remember(agent, c.ValidationTTL)
+    }
     return nil
 }
 
+// Successful credential validations by username
+// The credentials are stored hashed so a changed password for the same username revalidates
+type validationCache struct {
+    mu sync.Mutex
+    entries map[string]validationEntry
+}
+
+type validationEntry struct {
+    credentials [sha256.Size]byte
+    expires time.Time
+}
+
+func (v *validationCache) valid(agent SpireAgent) bool {
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    entry, ok := v.entries[agent.Username]
+    return ok && entry.credentials == credentialHash(agent) && time.Now().Before(entry.expires)
+}
+
+func (v *validationCache) remember(agent SpireAgent, ttl time.Duration) {
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    if v.entries == nil {
+        v.entries = make(map[string]validationEntry)
+    }
+    v.entries[agent.Username] = validationEntry{
+        credentials: credentialHash(agent),
+        expires: time.Now().Add(ttl),
+    }
+}
+
+func (v *validationCache) forget(agent SpireAgent) {
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    delete(v.entries, agent.Username)
+}
+
+func credentialHash(agent SpireAgent) [sha256.Size]byte {
+    return sha256.Sum256([]byte(agent.Username + ":" + agent.Password))
+}
+
 // Converts maps to JSON string
+// Keys are emitted in sorted order at every level (encoding/json sorts map keys), so equal
+// filters always produce byte-identical output, suitable for golden files and cache keys
 func ConvertFilter(filters map[string]interface{}) (string, error) {
     if filters == nil || len(filters) == 0 {
         return "", nil
@@ -157,93 +767,481 @@ func ConvertFilter(filters map[string]interface{}) (string, error) {
 }
 
 // FetchSpireRecords handles pagination into a slice of specific structs [T]
+//
+// Deprecated: use FetchSpireDataTyped
 func FetchSpireRecords[T any](c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent) ([]T, error) {
-    const maxLimit = 10000
-    filter, _ := ConvertFilter(filters)
-    baseURL, _ := url.Parse(endpoint)
-    q := baseURL.Query()
-    q.Set("limit", fmt.Sprintf("%d", maxLimit))
-    if filter != "" { q.Set("filter", filter) }
-    baseURL.RawQuery = q.Encode()
+    return FetchSpireDataTypedContext[T](context.Background(), c, endpoint, filters, agent)
+}
 
-    initialResponse, err := SpireRequestGeneric[T](c, baseURL.String(), agent, "GET", nil)
-    if err != nil { return nil, err }
+// FetchSpireRecordsContext is FetchSpireRecords bound to ctx
+//
+// Deprecated: use FetchSpireDataTypedContext
+func FetchSpireRecordsContext[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent) ([]T, error) {
+    return FetchSpireDataTypedContext[T](ctx, c, endpoint, filters, agent)
+}
 
-    records := initialResponse.Records
-    count := int(initialResponse.Count)
-    if count <= maxLimit { return records, nil }
+// FetchSpireDataTyped gets ALL records for a given endpoint like FetchSpireData, decoding each record into T
+// T is typically a struct with json tags for the fields of interest, giving compile-time field access
+func FetchSpireDataTyped[T any](c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent) ([]T, error) {
+    return FetchSpireDataTypedContext[T](context.Background(), c, endpoint, filters, agent)
+}
 
-    allRecords := make([]T, 0, count)
-    allRecords = append(allRecords, records...)
+// FetchSpireDataTypedContext is FetchSpireDataTyped bound to ctx, stopping pagination as soon as ctx is done
+func FetchSpireDataTypedContext[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent) ([]T, error) {
+    return FetchSpireDataTypedWithOptions[T](ctx, c, endpoint, filters, agent, FetchOptions{})
+}
+
+// FetchSpireDataTypedWithOptions is FetchSpireDataTypedContext customised by opts
+// A deadline on ctx bounds the whole pagination loop; if it passes, or a page fails, after some pages
+// have arrived, those records are returned together with a *PartialResultError
+func FetchSpireDataTypedWithOptions[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions) ([]T, error) {
+    records, _, err := fetchAll[T](ctx, c, endpoint, filters, agent, opts)
+    return records, err
+}
 
-    for start := maxLimit; len(allRecords) < count; start += maxLimit {
-        q.Set("start", fmt.Sprintf("%d", start))
-        baseURL.RawQuery = q.Encode()
-        nextPage, err := SpireRequestGeneric[T](c, baseURL.String(), agent, "GET", nil)
-        if err != nil { return nil, err }
-        allRecords = append(allRecords, nextPage.Records...)
+// Collects every page along with the total count reported by the first
+func fetchAll[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions) ([]T, int, error) {
+    var allRecords []T
+    total := 0
+    err := walkPages[T](ctx, c, endpoint, filters, agent, opts, true, func(page []T, count int) error {
+        if allRecords == nil {
+            allRecords = make([]T, 0, c.preallocate(count-opts.Start, len(page)))
+            total = count
+        }
+        allRecords = append(allRecords, page...)
+        return nil
+    })
+    if err != nil {
+        if len(allRecords) > 0 {
+            return allRecords, total, &PartialResultError{
+                Received: len(allRecords),
+                Total: total,
+                Next: opts.Start + len(allRecords),
+                Err: err,
+            }
+        }
+        return nil, 0, err
     }
-    return allRecords, nil
+    return allRecords, total, nil
 }
 
-// Gets ALL records for a given endpoint
-func (c *SpireClient) FetchSpireData(endpoint string, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
-    const maxLimit = 10000
+// Capacity to reserve for remaining records given the first page's size, trusting the server's count
+// only as far as MaxPages allows so that a corrupted count cannot force a huge allocation
+func (c *SpireClient) preallocate(remaining, pageSize int) int {
+    maxPages := c.maxPages()
+    if maxPages == 0 || pageSize == 0 {
+        return pageSize
+    }
+    if remaining/pageSize < maxPages {
+        return max(remaining, pageSize)
+    }
+    return maxPages * pageSize
+}
+
+// FetchSpireDataTypedStream is FetchSpireDataTypedWithOptions handing each page to fn as it arrives
+// instead of buffering every record, so callers can process and discard pages
+// Pages are fetched sequentially regardless of SpireClient.Concurrency
+// Iteration stops at the first error returned by fn, which is returned unchanged; a page failing
+// after earlier pages were handled returns a *PartialResultError whose Next resumes the stream
+func FetchSpireDataTypedStream[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions, fn func(page []T) error) error {
+    return streamPages[T](ctx, c, endpoint, filters, agent, opts, func(page []T, _ int) error {
+        return fn(page)
+    })
+}
 
-	filter, err := ConvertFilter(filters)
+// Walks the pages sequentially, reporting a page failing after others were handed to fn as a
+// *PartialResultError so the caller knows where to resume; errors from fn are returned unchanged
+func streamPages[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions, fn func(page []T, count int) error) error {
+    received, total := 0, 0
+    var fnErr error
+    err := walkPages[T](ctx, c, endpoint, filters, agent, opts, false, func(page []T, count int) error {
+        total = count
+        if fnErr = fn(page, count); fnErr != nil {
+            return fnErr
+        }
+        received += len(page)
+        return nil
+    })
+    if err != nil && fnErr == nil && received > 0 {
+        return &PartialResultError{Received: received, Total: total, Next: opts.Start + received, Err: err}
+    }
+    return err
+}
+
+// Builds the URL of the page of endpoint starting at opts.Start, returning the query separately so
+// later pages can change the start
+func (c *SpireClient) listURL(endpoint string, filters map[string]interface{}, limit int, opts FetchOptions) (*url.URL, url.Values, error) {
+    if !opts.lookup {
+        filters = c.effectiveFilter(endpoint, filters)
+    }
+    if c.ValidateFilters {
+        if err := ValidateFilter(filters); err != nil {
+            return nil, nil, err
+        }
+    }
+    filter, err := ConvertFilter(filters)
     if err != nil {
-        return nil, fmt.Errorf("could not convert filter: %w", err)
+        return nil, nil, fmt.Errorf("could not convert filter: %w", err)
     }
-	
-	baseURL, err := url.Parse(endpoint)
+
+    baseURL, err := url.Parse(endpoint)
     if err != nil {
-        return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+        return nil, nil, fmt.Errorf("invalid endpoint URL: %w", err)
+    }
+
+    if opts.Start < 0 {
+        return nil, nil, fmt.Errorf("invalid start offset %d: must not be negative", opts.Start)
     }
 
-	q := baseURL.Query()
-    q.Set("limit", fmt.Sprintf("%d", maxLimit))
+    q := baseURL.Query()
+    q.Set("limit", fmt.Sprintf("%d", limit))
     if filter != "" {
         q.Set("filter", filter)
     }
+    if opts.Start > 0 {
+        q.Set("start", fmt.Sprintf("%d", opts.Start))
+    }
+    opts.apply(q)
+    baseURL.RawQuery = q.Encode()
+    return baseURL, q, nil
+}
 
-	baseURL.RawQuery = q.Encode()
-	
-	initialResponse, err := c.SpireRequest(baseURL.String(), agent, "GET", nil)
+// Resolves a nextPage link, which may be absolute or relative, against the page it came from
+func (c *SpireClient) nextPageURL(current, next string) (string, error) {
+    currentURL, err := c.endpointURL(current)
+    if err != nil {
+        return "", err
+    }
+    base, err := url.Parse(currentURL)
+    if err != nil {
+        return "", fmt.Errorf("invalid page URL: %w", err)
+    }
+    ref, err := url.Parse(next)
     if err != nil {
-        return nil, fmt.Errorf("error making initial Spire request: %w", err)
+        return "", fmt.Errorf("invalid nextPage link %q: %w", next, err)
     }
+    return base.ResolveReference(ref).String(), nil
+}
 
-	records := initialResponse.Records
-    count := int(initialResponse.Count)
+// Shared pagination loop, calling fn with each page in offset order along with the total count
+// Each page starts where the records received so far end, so a server that caps pages below
+// the requested limit still has every page fetched through the last
+// When Spire links each page to the next with nextPage, those links are followed instead
+// When concurrent is set and SpireClient.Concurrency allows, the pages after the first are
+// fetched in parallel and passed to fn together
+func walkPages[T any](ctx context.Context, c *SpireClient, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions, concurrent bool, fn func(page []T, count int) error) error {
+    limit, err := c.pageLimit(opts)
+    if err != nil {
+        return err
+    }
 
-    if count <= maxLimit {
-        return records, nil
+    baseURL, q, err := c.listURL(endpoint, filters, limit, opts)
+    if err != nil {
+        return err
     }
 
-    allRecords := make([]map[string]interface{}, 0, count)
-    allRecords = append(allRecords, records...)
+    initialResponse, err := SpireRequestGenericContext[T](ctx, c, baseURL.String(), agent, "GET", nil)
+    if err != nil {
+        return fmt.Errorf("error making initial Spire request: %w", err)
+    }
 
-	for start := maxLimit; len(allRecords) < count; start += maxLimit {
-		q.Set("start", fmt.Sprintf("%d", start))
-		baseURL.RawQuery = q.Encode()
+    records := initialResponse.Records
+    count := initialResponse.Count
+    if err := fn(records, count); err != nil {
+        return err
+    }
 
-		nextPageResponse, err := c.SpireRequest(baseURL.String(), agent, "GET", nil)
-		if err != nil {
-			return nil, fmt.Errorf("error making Spire request starting at %d: %w", start, err)
-		}
-		allRecords = append(allRecords, nextPageResponse.Records...)
+    received := opts.Start + len(records)
+    if received >= count {
+        return nil
+    }
 
-        if len(nextPageResponse.Records) == 0 {
+    maxPages := c.maxPages()
+    if concurrent && c.Concurrency > 1 && len(records) > 0 && initialResponse.NextPage == "" {
+        end := count
+        if maxPages > 0 && (count-received+len(records)-1)/len(records) >= maxPages {
+            end = received + (maxPages-1)*len(records)
+        }
+        rest, err := fetchPagesConcurrently[T](ctx, c, *baseURL, q, agent, received, len(records), end)
+        if len(rest) > 0 {
+            if fnErr := fn(rest, count); fnErr != nil {
+                return fnErr
+            }
+        }
+        if err == nil && end < count {
+            return fmt.Errorf("stopped after %d pages of %d records: %w", maxPages, count, ErrMaxPagesExceeded)
+        }
+        return err
+    }
+
+    pageEndpoint, next := baseURL.String(), initialResponse.NextPage
+    for pages := 1; received < count; pages++ {
+        start := received
+        if err := ctx.Err(); err != nil {
+            return fmt.Errorf("fetch cancelled at page starting %d: %w", start, err)
+        }
+        if maxPages > 0 && pages >= maxPages {
+            return fmt.Errorf("stopped after %d pages of %d records: %w", pages, count, ErrMaxPagesExceeded)
+        }
+        if next != "" {
+            pageEndpoint, err = c.nextPageURL(pageEndpoint, next)
+            if err != nil {
+                return err
+            }
+        } else {
+            q.Set("start", fmt.Sprintf("%d", start))
+            baseURL.RawQuery = q.Encode()
+            pageEndpoint = baseURL.String()
+        }
+
+        nextPage, err := SpireRequestGenericContext[T](ctx, c, pageEndpoint, agent, "GET", nil)
+        if err != nil {
+            return fmt.Errorf("error making Spire request starting at %d: %w", start, err)
+        }
+        next = nextPage.NextPage
+
+        if len(nextPage.Records) == 0 {
             log.Printf("Warning: Spire API returned 0 records at offset %d, breaking pagination loop.", start)
             break
         }
-	}
-	return allRecords, nil
+        if err := fn(nextPage.Records, count); err != nil {
+            return err
+        }
+        received += len(nextPage.Records)
+    }
+    return nil
+}
+
+// Gets ALL records for a given endpoint
+// endpoint is a path relative to RootURL such as "/sales/orders"; an absolute URL is only accepted
+// when it points at the RootURL host
+// If a page fails after others have arrived, the records so far are returned with a *PartialResultError
+func (c *SpireClient) FetchSpireData(endpoint string, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(context.Background(), endpoint, filters, agent)
+}
+
+// FetchSpireDataContext is FetchSpireData bound to ctx
+// Cancelling ctx aborts the in-flight page request and stops pagination immediately
+func (c *SpireClient) FetchSpireDataContext(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataWithOptions(ctx, endpoint, filters, agent, FetchOptions{})
+}
+
+// FetchSpireDataWithLimit is FetchSpireDataContext requesting an explicit number of records per page,
+// overriding SpireClient.PageLimit for this call
+func (c *SpireClient) FetchSpireDataWithLimit(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent, limit int) ([]map[string]interface{}, error) {
+    if limit <= 0 {
+        return nil, fmt.Errorf("invalid page limit %d: must be greater than 0", limit)
+    }
+    return c.FetchSpireDataWithOptions(ctx, endpoint, filters, agent, FetchOptions{Limit: limit})
+}
+
+// FetchSpireDataWithOptions is FetchSpireDataContext customised by opts, e.g. to sort the records
+// With SpireClient.CacheTTL set, a repeat of the same call within the TTL is answered from memory
+func (c *SpireClient) FetchSpireDataWithOptions(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions) ([]map[string]interface{}, error) {
+    if c.CacheTTL <= 0 || opts.lookup {
+        return FetchSpireDataTypedWithOptions[map[string]interface{}](ctx, c, endpoint, filters, agent, opts)
+    }
+
+    filter, err := ConvertFilter(c.effectiveFilter(endpoint, filters))
+    if err != nil {
+        return nil, fmt.Errorf("could not convert filter: %w", err)
+    }
+    key := cacheKey(endpoint, filter, agent, opts)
+    if records, ok := c.cache.get(key); ok {
+        return records, nil
+    }
+
+    records, err := FetchSpireDataTypedWithOptions[map[string]interface{}](ctx, c, endpoint, filters, agent, opts)
+    if err != nil {
+        return records, err
+    }
+    size := c.CacheSize
+    if size <= 0 {
+        size = defaultCacheSize
+    }
+    c.cache.put(key, records, c.CacheTTL, size)
+    return records, nil
+}
+
+// FetchSpireDataWithCount is FetchSpireDataContext also returning the total number of matching records
+// reported by the first page, e.g. to drive a progress bar or spot a filter matching more than expected
+func (c *SpireClient) FetchSpireDataWithCount(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, int, error) {
+    return fetchAll[map[string]interface{}](ctx, c, endpoint, filters, agent, FetchOptions{})
+}
+
+// FetchSpireDataInto gets ALL records for a given endpoint like FetchSpireDataContext, decoding them into
+// dest, which must be a non-nil pointer to a slice, e.g. *[]SalesOrder
+// It offers typed results without a type parameter, for example when the type is only known at run time
+func (c *SpireClient) FetchSpireDataInto(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent, dest interface{}) error {
+    v := reflect.ValueOf(dest)
+    if v.Kind() != reflect.Pointer || v.IsNil() {
+        return fmt.Errorf("FetchSpireDataInto: dest must be a non-nil pointer to a slice, got %T", dest)
+    }
+    if v.Elem().Kind() != reflect.Slice {
+        return fmt.Errorf("FetchSpireDataInto: dest must point to a slice, got %T", dest)
+    }
+
+    records, err := FetchSpireDataTypedContext[json.RawMessage](ctx, c, endpoint, filters, agent)
+    if err != nil {
+        return err
+    }
+
+    body, err := json.Marshal(records)
+    if err != nil {
+        return fmt.Errorf("failed to collect records: %w", err)
+    }
+    if string(body) == "null" {
+        body = []byte("[]")
+    }
+    if err := unmarshalJSON(body, dest, c.UseNumber); err != nil {
+        return fmt.Errorf("error decoding records into %T: %w", dest, err)
+    }
+    return nil
+}
+
+// FetchPage gets the single page of endpoint starting at opts.Start, holding up to the page limit of
+// records, along with the total count, for callers driving pagination themselves, e.g. a paged UI
+// The next page starts at opts.Start plus the records received, until that reaches Count
+func (c *SpireClient) FetchPage(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions) (SpireResponse, error) {
+    limit, err := c.pageLimit(opts)
+    if err != nil {
+        return SpireResponse{}, err
+    }
+    pageURL, _, err := c.listURL(endpoint, filters, limit, opts)
+    if err != nil {
+        return SpireResponse{}, err
+    }
+    return c.SpireRequestContext(ctx, pageURL.String(), agent, "GET", nil)
+}
+
+// GetCount returns how many records of endpoint match filters, as reported by Spire, while
+// transferring only a single record
+func (c *SpireClient) GetCount(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent) (int, error) {
+    countURL, _, err := c.listURL(endpoint, filters, 1, FetchOptions{})
+    if err != nil {
+        return 0, err
+    }
+
+    resp, err := c.SpireRequestContext(ctx, countURL.String(), agent, "GET", nil)
+    if err != nil {
+        return 0, err
+    }
+    return resp.Count, nil
+}
+
+// FetchSpireDataStream walks every page of an endpoint like FetchSpireDataWithOptions, passing each page
+// to fn as it arrives rather than holding all records in memory
+// Iteration stops at the first error returned by fn, which is returned unchanged
+func (c *SpireClient) FetchSpireDataStream(ctx context.Context, endpoint string, filters map[string]interface{}, agent SpireAgent, opts FetchOptions, fn func(page []map[string]interface{}) error) error {
+    return FetchSpireDataTypedStream[map[string]interface{}](ctx, c, endpoint, filters, agent, opts, fn)
 }
 
 // Sends a POST request to Spire to create a new sales order
-// The payload should be the fully prepared sales order body structure
+// The payload should be the fully prepared sales order body structure, such as a SalesOrder
 func (c *SpireClient) CreateSalesOrder(agent SpireAgent, payload interface{}) (SpireResponse, error) {
-    return c.SpireRequest("/sales/orders", agent, "POST", payload)
+    return c.CreateSalesOrderContext(context.Background(), agent, payload)
 }
 
+// CreateSalesOrderContext is CreateSalesOrder bound to ctx
+func (c *SpireClient) CreateSalesOrderContext(ctx context.Context, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    if c.ValidateSalesOrders {
+        if err := ValidateSalesOrder(payload); err != nil {
+            return SpireResponse{}, err
+        }
+    }
+    return c.CreateRecord(ctx, "/sales/orders", agent, payload)
+}
+
+// CreateSalesOrderWithID creates a sales order and returns its id, taken from the Location header Spire sends with the 201
+func (c *SpireClient) CreateSalesOrderWithID(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    if c.ValidateSalesOrders {
+        if err := ValidateSalesOrder(payload); err != nil {
+            return "", err
+        }
+    }
+    return c.CreateRecordWithID(ctx, "/sales/orders", agent, payload)
+}
+
+// CreateSalesOrderIdempotent creates a sales order unless one already carries the same client reference,
+// so a create retried after a crash doesn't duplicate the order
+// referenceField names the order field holding the reference, e.g. "referenceNo", and payload must set
+// it to reference; the reference is also sent as the Idempotency-Key header
+// It returns the id of the existing or new order, and whether it was created by this call
+// Two concurrent calls with the same reference can still both create, so callers should not race them
+func (c *SpireClient) CreateSalesOrderIdempotent(ctx context.Context, agent SpireAgent, referenceField, reference string, payload interface{}) (string, bool, error) {
+    if referenceField == "" || reference == "" {
+        return "", false, fmt.Errorf("idempotent create requires a reference field and value")
+    }
+
+    existing, err := c.FetchPage(ctx, "/sales/orders", Filter{referenceField: reference}, agent, FetchOptions{Limit: 1, lookup: true})
+    if err != nil {
+        return "", false, fmt.Errorf("error checking for existing sales order %s=%s: %w", referenceField, reference, err)
+    }
+    if len(existing.Records) > 0 {
+        return idString(existing.Records[0]["id"]), false, nil
+    }
+
+    id, err := c.CreateSalesOrderWithID(WithIdempotencyKey(ctx, reference), agent, payload)
+    if err != nil {
+        return "", false, err
+    }
+    return id, true, nil
+}
+
+// GetSalesOrder fetches a single sales order by its numeric id
+// A missing order returns an error matching ErrNotFound
+func (c *SpireClient) GetSalesOrder(ctx context.Context, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.GetRecord(ctx, "/sales/orders", id, agent)
+}
+
+// UpdateSalesOrder replaces the sales order with the given id using a PUT of the full order
+// The updated order Spire returns is the single record of the response
+func (c *SpireClient) UpdateSalesOrder(ctx context.Context, id string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.UpdateRecord(ctx, "/sales/orders", id, agent, payload)
+}
+
+// CloseSalesOrder marks the sales order with the given id closed, fetching it and PUTting it back with
+// its status set to SalesOrderStatusClosed
+// An order that is already closed is left untouched and reported by alreadyClosed rather than an error
+func (c *SpireClient) CloseSalesOrder(ctx context.Context, id string, agent SpireAgent) (alreadyClosed bool, err error) {
+    order, err := c.GetSalesOrder(ctx, id, agent)
+    if err != nil {
+        return false, err
+    }
+    if status, _ := order["status"].(string); status == SalesOrderStatusClosed {
+        return true, nil
+    }
+
+    order["status"] = SalesOrderStatusClosed
+    if _, err := c.UpdateSalesOrder(ctx, id, agent, order); err != nil {
+        return false, fmt.Errorf("error closing sales order %s: %w", id, err)
+    }
+    return false, nil
+}
+
+// DeleteSalesOrder deletes the sales order with the given id
+func (c *SpireClient) DeleteSalesOrder(ctx context.Context, id string, agent SpireAgent) error {
+    return c.DeleteRecord(ctx, "/sales/orders", id, agent)
+}
+
+// CreateSalesOrders creates an order from every payload, continuing past individual failures
+// results[i] holds the id or error for payloads[i]; the error joins all failures and is nil when every create succeeded
+func (c *SpireClient) CreateSalesOrders(ctx context.Context, agent SpireAgent, payloads []interface{}) ([]CreateResult, error) {
+    return c.CreateSalesOrdersWithOptions(ctx, agent, payloads, CreateOptions{})
+}
+
+// CreateSalesOrdersWithOptions is CreateSalesOrders customised by opts, e.g. to create in parallel
+func (c *SpireClient) CreateSalesOrdersWithOptions(ctx context.Context, agent SpireAgent, payloads []interface{}, opts CreateOptions) ([]CreateResult, error) {
+    return c.createBatch(ctx, c.CreateSalesOrderWithID, payloads, agent, opts)
+}
+
+// DeleteSalesOrders deletes every order in ids, continuing past individual failures
+// The result reports each id's outcome; the error joins all failures and is nil when every delete succeeded
+func (c *SpireClient) DeleteSalesOrders(ctx context.Context, agent SpireAgent, ids []string) (DeleteResult, error) {
+    return c.DeleteSalesOrdersWithOptions(ctx, agent, ids, DeleteOptions{})
+}
+
+// DeleteSalesOrdersWithOptions is DeleteSalesOrders customised by opts, e.g. to delete in parallel
+func (c *SpireClient) DeleteSalesOrdersWithOptions(ctx context.Context, agent SpireAgent, ids []string, opts DeleteOptions) (DeleteResult, error) {
+    return c.deleteBatch(ctx, "/sales/orders", ids, agent, opts)
+}
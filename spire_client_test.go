@@ -0,0 +1,213 @@
+package spireclient
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func TestCreateSalesOrderIdempotentBypassesCache(t *testing.T) {
+    var mu sync.Mutex
+    gets, posts := 0, 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        mu.Lock()
+        defer mu.Unlock()
+        switch r.Method {
+        case http.MethodGet:
+            gets++
+            if posts == 0 {
+                w.Write([]byte(`{"records":[],"count":0}`))
+                return
+            }
+            w.Write([]byte(`{"records":[{"id":7,"referenceNo":"R1"}],"count":1}`))
+        case http.MethodPost:
+            posts++
+            w.Header().Set("Location", "/sales/orders/7")
+            w.WriteHeader(http.StatusCreated)
+        }
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL, WithResponseCache(time.Minute, 10))
+    order := NewSalesOrder("C1")
+    order.ReferenceNo = "R1"
+    for i, wantCreated := range []bool{true, false} {
+        id, created, err := client.CreateSalesOrderIdempotent(context.Background(), SpireAgent{}, "referenceNo", "R1", order)
+        if err != nil {
+            t.Fatalf("call %d: %v", i, err)
+        }
+        if id != "7" || created != wantCreated {
+            t.Fatalf("call %d: got id %q created %v, want 7 and %v", i, id, created, wantCreated)
+        }
+    }
+    if gets != 2 || posts != 1 {
+        t.Fatalf("got %d GETs and %d POSTs, want 2 and 1", gets, posts)
+    }
+}
+
+func TestUpdateSalesOrder(t *testing.T) {
+    var method, path string
+    var body map[string]interface{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        method, path = r.Method, r.URL.Path
+        data, _ := io.ReadAll(r.Body)
+        if err := json.Unmarshal(data, &body); err != nil {
+            t.Errorf("payload %s is not JSON: %v", data, err)
+        }
+        if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+            t.Errorf("Content-Type = %q, want application/json", ct)
+        }
+        w.Write([]byte(`{"records":[{"id":42,"status":"H"}],"count":1}`))
+    }))
+    defer server.Close()
+
+    resp, err := NewSpireClient(server.URL).UpdateSalesOrder(context.Background(), "42", SpireAgent{}, map[string]interface{}{
+        "status": "H",
+        "customerPO": "PO-9",
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if method != http.MethodPut || path != "/sales/orders/42" {
+        t.Errorf("sent %s %s, want PUT /sales/orders/42", method, path)
+    }
+    if body["status"] != "H" || body["customerPO"] != "PO-9" || len(body) != 2 {
+        t.Errorf("payload = %v, want status H and customerPO PO-9", body)
+    }
+    if len(resp.Records) != 1 || resp.Records[0]["status"] != "H" {
+        t.Errorf("records = %v, want the updated order", resp.Records)
+    }
+}
+
+func TestErrorsNeverContainCredentials(t *testing.T) {
+    agent := SpireAgent{Username: "apiuser", Password: "s3cr3t-pa55"}
+    encoded := strings.TrimPrefix(agent.BasicAuthHeader(), "Basic ")
+
+    tests := []struct {
+        name string
+        handler http.HandlerFunc
+    }{
+        {"error status echoing the request", func(w http.ResponseWriter, r *http.Request) {
+            w.WriteHeader(http.StatusUnauthorized)
+            fmt.Fprintf(w, `{"message":"bad credentials %s for %s"}`, r.Header.Get("Authorization"), agent.Password)
+        }},
+        {"body that is not JSON", func(w http.ResponseWriter, r *http.Request) {
+            fmt.Fprintf(w, `<html>debug: Authorization=%s password=%s</html>`, r.Header.Get("Authorization"), agent.Password)
+        }},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            server := httptest.NewServer(tt.handler)
+            defer server.Close()
+
+            _, err := NewSpireClient(server.URL).SpireRequestContext(context.Background(), "/sales/orders", agent, http.MethodGet, nil)
+            if err == nil {
+                t.Fatal("got no error")
+            }
+            for _, text := range []string{err.Error(), fmt.Sprintf("%+v", err), fmt.Sprintf("%#v", err)} {
+                if strings.Contains(text, encoded) || strings.Contains(text, agent.Password) {
+                    t.Errorf("error reveals the credentials: %s", text)
+                }
+            }
+        })
+    }
+
+    server := httptest.NewServer(http.NotFoundHandler())
+    server.Close()
+    _, err := NewSpireClient(server.URL).SpireRequestContext(context.Background(), "/sales/orders", agent, http.MethodGet, nil)
+    if err == nil || strings.Contains(err.Error(), encoded) || strings.Contains(err.Error(), agent.Password) {
+        t.Errorf("connection error %v reveals the credentials or is missing", err)
+    }
+}
+
+func TestShortPasswordDoesNotGarbleErrors(t *testing.T) {
+    agent := SpireAgent{Username: "apiuser", Password: "e"}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusBadRequest)
+        fmt.Fprintf(w, `{"message":"order reference required","auth":"%s"}`, r.Header.Get("Authorization"))
+    }))
+    defer server.Close()
+
+    _, err := NewSpireClient(server.URL).SpireRequestContext(context.Background(), "/sales/orders", agent, http.MethodPost, map[string]interface{}{})
+    if err == nil {
+        t.Fatal("got no error")
+    }
+    if !strings.Contains(err.Error(), `{"message":"order reference required"`) {
+        t.Errorf("error message garbled: %v", err)
+    }
+    if encoded := strings.TrimPrefix(agent.BasicAuthHeader(), "Basic "); strings.Contains(err.Error(), encoded) {
+        t.Errorf("error reveals the encoded credentials: %v", err)
+    }
+}
+
+func TestConvertFilterDeterministic(t *testing.T) {
+    a := map[string]interface{}{}
+    a["whse"] = "00"
+    a["status"] = map[string]interface{}{"$in": []interface{}{"O", "H"}}
+    a["$and"] = []interface{}{
+        map[string]interface{}{"onHand": map[string]interface{}{"$gt": 1, "$lt": 100}},
+        map[string]interface{}{"$or": []interface{}{
+            map[string]interface{}{"partNo": "A", "desc": "x"},
+            map[string]interface{}{"partNo": "B"},
+        }},
+    }
+
+    b := map[string]interface{}{}
+    b["$and"] = []interface{}{
+        map[string]interface{}{"onHand": map[string]interface{}{"$lt": 100, "$gt": 1}},
+        map[string]interface{}{"$or": []interface{}{
+            map[string]interface{}{"desc": "x", "partNo": "A"},
+            map[string]interface{}{"partNo": "B"},
+        }},
+    }
+    b["status"] = map[string]interface{}{"$in": []interface{}{"O", "H"}}
+    b["whse"] = "00"
+
+    want, err := ConvertFilter(a)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 100; i++ {
+        for _, filter := range []map[string]interface{}{a, b} {
+            got, err := ConvertFilter(filter)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got != want {
+                t.Fatalf("call %d: ConvertFilter gave\n%s\nthen\n%s", i, want, got)
+            }
+        }
+    }
+    if want != `{"$and":[{"onHand":{"$gt":1,"$lt":100}},{"$or":[{"desc":"x","partNo":"A"},{"partNo":"B"}]}],"status":{"$in":["O","H"]},"whse":"00"}` {
+        t.Errorf("keys not sorted: %s", want)
+    }
+}
+
+func TestPartialContentIsDecoded(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusPartialContent)
+        w.Write([]byte(`{"records":[{"id":1},{"id":2}],"count":5}`))
+    }))
+    defer server.Close()
+
+    client := NewSpireClient(server.URL)
+    resp, err := client.SpireRequestContext(context.Background(), "/sales/orders", SpireAgent{}, http.MethodGet, nil)
+    if err != nil {
+        t.Fatalf("206 returned %v, want success", err)
+    }
+    if resp.StatusCode != http.StatusPartialContent || len(resp.Records) != 2 || resp.Count != 5 {
+        t.Errorf("got status %d with %d of %d records, want 206 with 2 of 5", resp.StatusCode, len(resp.Records), resp.Count)
+    }
+
+    raw, err := client.SpireRequestRaw(context.Background(), "/sales/orders", SpireAgent{}, http.MethodGet, nil)
+    if err != nil || raw.StatusCode != http.StatusPartialContent || len(raw.Response.Records) != 2 {
+        t.Errorf("raw request got status %d with %d records and %v, want 206 with 2", raw.StatusCode, len(raw.Response.Records), err)
+    }
+}
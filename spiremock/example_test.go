@@ -0,0 +1,66 @@
+package spiremock_test
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "testing"
+
+    spireclient "github.com/morganmwalker/go-spire-api-client"
+    "github.com/morganmwalker/go-spire-api-client/spiremock"
+)
+
+func TestCreateOrder(t *testing.T) {
+    server := spiremock.NewServer(t)
+    server.Handle("POST", "/sales/orders", spiremock.Response{
+        StatusCode: http.StatusCreated,
+        Header: http.Header{"Location": {server.URL + "/sales/orders/42"}},
+    })
+
+    order := spireclient.NewSalesOrder("C1")
+    id, err := server.Client().CreateSalesOrderWithID(context.Background(), spireclient.SpireAgent{}, order)
+    if err != nil || id != "42" {
+        t.Fatalf("got %q, %v", id, err)
+    }
+
+    got := server.Requests()
+    if len(got) != 1 {
+        t.Fatalf("expected one request, got %d", len(got))
+    }
+    var sent map[string]interface{}
+    if err := json.Unmarshal(got[0].Body, &sent); err != nil {
+        t.Fatalf("payload %s is not JSON: %v", got[0].Body, err)
+    }
+    if customer, _ := sent["customer"].(map[string]interface{}); customer["customerNo"] != "C1" {
+        t.Errorf("sent customer %v, want customerNo C1", sent["customer"])
+    }
+}
+
+func TestFetchPaginates(t *testing.T) {
+    server := spiremock.NewServer(t)
+    server.HandleRecords("GET", "/inventory/items",
+        map[string]interface{}{"partNo": "A"},
+        map[string]interface{}{"partNo": "B"},
+        map[string]interface{}{"partNo": "C"},
+    )
+
+    records, err := server.Client(spireclient.WithPageLimit(2)).FetchSpireData("/inventory/items", nil, spireclient.SpireAgent{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(records) != 3 || records[2]["partNo"] != "C" {
+        t.Fatalf("got %v, want parts A, B and C", records)
+    }
+    if got := len(server.Requests()); got != 2 {
+        t.Errorf("expected two page requests, got %d", got)
+    }
+}
+
+func TestUnregisteredRouteIsNotFound(t *testing.T) {
+    server := spiremock.NewServer(t)
+    _, err := server.Client().GetSalesOrder(context.Background(), "7", spireclient.SpireAgent{})
+    if !errors.Is(err, spireclient.ErrNotFound) {
+        t.Fatalf("got %v, want ErrNotFound", err)
+    }
+}
@@ -0,0 +1,168 @@
+// Package spiremock provides an in-process stand-in for the Spire API so code built on spireclient
+// can be unit tested without a live server
+//
+//  func TestCreateOrder(t *testing.T) {
+//      server := spiremock.NewServer(t)
+//      server.Handle("POST", "/sales/orders", spiremock.Response{
+//          StatusCode: http.StatusCreated,
+//          Header: http.Header{"Location": {server.URL + "/sales/orders/42"}},
+//      })
+//
+//      id, err := server.Client().CreateSalesOrderWithID(context.Background(), spireclient.SpireAgent{}, order)
+//      if err != nil || id != "42" {
+//          t.Fatalf("got %q, %v", id, err)
+//      }
+//      if got := server.Requests(); len(got) != 1 {
+//          t.Fatalf("expected one request, got %d", len(got))
+//      }
+//  }
+package spiremock
+
+import (
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "strconv"
+    "sync"
+    "testing"
+
+    spireclient "github.com/morganmwalker/go-spire-api-client"
+)
+
+// Request is a request received by the Server
+type Request struct {
+    Method string
+    Path string
+    Query url.Values
+    Header http.Header
+    Body []byte
+}
+
+// Response is the canned answer to a route
+type Response struct {
+    // Defaults to 200 when zero
+    StatusCode int
+    Header http.Header
+    // Marshaled to JSON unless nil, or written as is when it is a []byte or string
+    Body interface{}
+}
+
+// Server records every request and answers from routes registered with Handle and HandleRecords
+// Unregistered routes answer 404
+type Server struct {
+    *httptest.Server
+
+    mu sync.Mutex
+    requests []Request
+    routes map[string]http.HandlerFunc
+}
+
+// NewServer starts a Server that is closed when the test ends
+func NewServer(tb testing.TB) *Server {
+    s := &Server{routes: make(map[string]http.HandlerFunc)}
+    s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+    tb.Cleanup(s.Close)
+    return s
+}
+
+// Client returns a SpireClient pointed at the Server
+func (s *Server) Client(opts ...spireclient.Option) *spireclient.SpireClient {
+    opts = append([]spireclient.Option{spireclient.WithHTTPClient(s.Server.Client())}, opts...)
+    return spireclient.NewSpireClient(s.URL, opts...)
+}
+
+// Handle answers method requests to path with resp
+func (s *Server) Handle(method, path string, resp Response) {
+    s.route(method, path, func(w http.ResponseWriter, r *http.Request) {
+        writeResponse(w, resp)
+    })
+}
+
+// HandleRecords answers method requests to path with records in Spire's records/count wrapper,
+// honouring the start and limit query parameters so pagination can be exercised
+func (s *Server) HandleRecords(method, path string, records ...map[string]interface{}) {
+    s.route(method, path, func(w http.ResponseWriter, r *http.Request) {
+        start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+        limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+        if err != nil || limit <= 0 {
+            limit = len(records)
+        }
+
+        page := []map[string]interface{}{}
+        for i := start; i >= 0 && i < len(records) && i < start+limit; i++ {
+            page = append(page, records[i])
+        }
+        writeResponse(w, Response{Body: map[string]interface{}{
+            "records": page,
+            "count": len(records),
+        }})
+    })
+}
+
+// Requests returns the requests received so far, in order
+func (s *Server) Requests() []Request {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) route(method, path string, handler http.HandlerFunc) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.routes[method+" "+path] = handler
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+    body, _ := io.ReadAll(r.Body)
+
+    s.mu.Lock()
+    s.requests = append(s.requests, Request{
+        Method: r.Method,
+        Path: r.URL.Path,
+        Query: r.URL.Query(),
+        Header: r.Header.Clone(),
+        Body: body,
+    })
+    handler, ok := s.routes[r.Method+" "+r.URL.Path]
+    s.mu.Unlock()
+
+    if !ok {
+        http.Error(w, "spiremock: no route for "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+        return
+    }
+    handler(w, r)
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+    for key, values := range resp.Header {
+        for _, v := range values {
+            w.Header().Add(key, v)
+        }
+    }
+
+    var body []byte
+    switch b := resp.Body.(type) {
+    case nil:
+    case []byte:
+        body = b
+    case string:
+        body = []byte(b)
+    default:
+        encoded, err := json.Marshal(b)
+        if err != nil {
+            http.Error(w, "spiremock: "+err.Error(), http.StatusInternalServerError)
+            return
+        }
+        body = encoded
+        w.Header().Set("Content-Type", "application/json")
+    }
+
+    statusCode := resp.StatusCode
+    if statusCode == 0 {
+        statusCode = http.StatusOK
+    }
+    w.WriteHeader(statusCode)
+    w.Write(body)
+}
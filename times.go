@@ -0,0 +1,70 @@
+package spireclient
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Layouts Spire formats dates and times in, tried in order; times without a zone are read as UTC
+var spireTimeLayouts = []string{
+    "2006-01-02T15:04:05.999999999",
+    time.RFC3339Nano,
+    "2006-01-02 15:04:05.999999999",
+    time.DateOnly,
+}
+
+// ParseSpireTime converts a date or datetime field of a record, such as "2024-01-15" or
+// "2024-01-15T00:00:00", to a time.Time
+// A missing, null or empty value returns the zero time and no error, so check IsZero where it matters
+func ParseSpireTime(v interface{}) (time.Time, error) {
+    switch t := v.(type) {
+    case nil:
+        return time.Time{}, nil
+    case time.Time:
+        return t, nil
+    case SpireTime:
+        return t.Time, nil
+    case string:
+        s := strings.TrimSpace(t)
+        if s == "" {
+            return time.Time{}, nil
+        }
+        for _, layout := range spireTimeLayouts {
+            if parsed, err := time.Parse(layout, s); err == nil {
+                return parsed, nil
+            }
+        }
+        return time.Time{}, fmt.Errorf("invalid Spire date %q", t)
+    default:
+        return time.Time{}, fmt.Errorf("invalid Spire date: unexpected type %T", v)
+    }
+}
+
+// SpireTime is a time.Time that decodes from any of Spire's date and datetime formats, for fields of
+// structs used with FetchSpireDataTyped; null and "" decode to the zero time
+type SpireTime struct {
+    time.Time
+}
+
+func (t *SpireTime) UnmarshalJSON(data []byte) error {
+    var raw interface{}
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+    parsed, err := ParseSpireTime(raw)
+    if err != nil {
+        return err
+    }
+    t.Time = parsed
+    return nil
+}
+
+// MarshalJSON writes the time as Spire formats datetimes, or null for the zero time
+func (t SpireTime) MarshalJSON() ([]byte, error) {
+    if t.IsZero() {
+        return []byte("null"), nil
+    }
+    return json.Marshal(t.Format("2006-01-02T15:04:05"))
+}
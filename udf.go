@@ -0,0 +1,75 @@
+package spireclient
+
+import (
+    "fmt"
+    "strconv"
+)
+
+// UDF holds a record's user-defined fields by name, sent and received as the record's "udf" object
+type UDF map[string]interface{}
+
+// Copy of u with name set to value, so that records sharing u are left unchanged
+func (u UDF) with(name string, value interface{}) UDF {
+    udf := make(UDF, len(u)+1)
+    for k, v := range u {
+        udf[k] = v
+    }
+    udf[name] = value
+    return udf
+}
+
+// WithUDF returns the order with the user-defined field name set to value
+func (o SalesOrder) WithUDF(name string, value interface{}) SalesOrder {
+    o.UDF = o.UDF.with(name, value)
+    return o
+}
+
+// WithUDF returns the line with the user-defined field name set to value
+func (i SalesOrderItem) WithUDF(name string, value interface{}) SalesOrderItem {
+    i.UDF = i.UDF.with(name, value)
+    return i
+}
+
+// GetUDF returns the user-defined field name of a record fetched from Spire, and whether it is set
+func GetUDF(record map[string]interface{}, name string) (interface{}, bool) {
+    udf, ok := record["udf"].(map[string]interface{})
+    if !ok {
+        return nil, false
+    }
+    value, ok := udf[name]
+    if !ok || value == nil {
+        return nil, false
+    }
+    return value, true
+}
+
+// GetUDFString returns the user-defined field name of a record as a string, formatting numbers and booleans
+// An unset field returns "" and false
+func GetUDFString(record map[string]interface{}, name string) (string, bool) {
+    value, ok := GetUDF(record, name)
+    if !ok {
+        return "", false
+    }
+    switch v := value.(type) {
+    case string:
+        return v, true
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64), true
+    default:
+        return fmt.Sprint(v), true
+    }
+}
+
+// GetUDFFloat returns the user-defined field name of a record as a number, parsing numeric strings
+// An unset field returns 0 and false, a value that isn't a number an error
+func GetUDFFloat(record map[string]interface{}, name string) (float64, bool, error) {
+    value, ok := GetUDF(record, name)
+    if !ok {
+        return 0, false, nil
+    }
+    f, err := toFloat(value)
+    if err != nil {
+        return 0, true, fmt.Errorf("udf %s: %w", name, err)
+    }
+    return f, true, nil
+}
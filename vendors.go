@@ -0,0 +1,36 @@
+package spireclient
+
+import (
+    "context"
+)
+
+const vendorsEndpoint = "/vendors"
+
+// Vendor holds the commonly used vendor fields, for use with FetchSpireDataTyped on /vendors
+type Vendor struct {
+    ID int `json:"id,omitempty"`
+    VendorNo string `json:"vendorNo"`
+    Name string `json:"name"`
+    DefaultCurrency string `json:"defaultCurrency,omitempty"`
+}
+
+// GetVendor fetches a single vendor by its numeric id
+// A missing vendor returns an error matching ErrNotFound
+func (c *SpireClient) GetVendor(ctx context.Context, id string, agent SpireAgent) (map[string]interface{}, error) {
+    return c.GetRecord(ctx, vendorsEndpoint, id, agent)
+}
+
+// ListVendors gets ALL vendors matching filters, paginating like FetchSpireData
+func (c *SpireClient) ListVendors(ctx context.Context, filters map[string]interface{}, agent SpireAgent) ([]map[string]interface{}, error) {
+    return c.FetchSpireDataContext(ctx, vendorsEndpoint, filters, agent)
+}
+
+// CreateVendor creates a vendor, such as a Vendor, and returns its id, taken from the Location header
+func (c *SpireClient) CreateVendor(ctx context.Context, agent SpireAgent, payload interface{}) (string, error) {
+    return c.CreateRecordWithID(ctx, vendorsEndpoint, agent, payload)
+}
+
+// UpdateVendor replaces the vendor with the given id using a PUT of the full vendor
+func (c *SpireClient) UpdateVendor(ctx context.Context, id string, agent SpireAgent, payload interface{}) (SpireResponse, error) {
+    return c.UpdateRecord(ctx, vendorsEndpoint, id, agent, payload)
+}